@@ -0,0 +1,172 @@
+package async
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock 时钟 (调度器使用, 测试时可替换为 FakeClock)
+type Clock interface {
+	Now() time.Time                      // 当前时间
+	NewTimer(d time.Duration) ClockTimer // 新建定时器
+}
+
+// ClockTimer 时钟定时器
+type ClockTimer interface {
+	C() <-chan time.Time // 到期信号
+	Stop() bool          // 停止定时器 (已到期或已停止时返回 false)
+}
+
+// ================================ 系统时钟 ================================= //
+
+// realClock 系统时钟
+type realClock struct{}
+
+// RealClock 系统时钟
+var RealClock Clock = realClock{}
+
+// Now 当前时间
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// NewTimer 新建定时器
+func (realClock) NewTimer(d time.Duration) ClockTimer {
+	return &realTimer{timer: time.NewTimer(d)}
+}
+
+// realTimer 系统定时器
+type realTimer struct {
+	timer *time.Timer
+}
+
+// C 到期信号
+func (t *realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop 停止定时器
+func (t *realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+// ================================ 模拟时钟 ================================= //
+
+// FakeClock 模拟时钟 (时间只在调用 Advance/Set 时推进, 用于确定性测试)
+type FakeClock struct {
+	lock   sync.Mutex
+	cond   *sync.Cond
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFakeClock 新建模拟时钟
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.cond = sync.NewCond(&c.lock)
+	return c
+}
+
+// Now 当前时间
+func (c *FakeClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// NewTimer 新建定时器
+func (c *FakeClock) NewTimer(d time.Duration) ClockTimer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	t := &fakeTimer{clock: c, when: c.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- c.now
+		return t
+	}
+	c.timers = append(c.timers, t)
+	c.cond.Broadcast()
+	return t
+}
+
+// Advance 推进时间, 触发到期定时器
+func (c *FakeClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.fire(c.now.Add(d))
+}
+
+// Set 设置当前时间 (不允许回退), 触发到期定时器
+func (c *FakeClock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if now.Before(c.now) {
+		return
+	}
+	c.fire(now)
+}
+
+// Timers 等待中的定时器数
+func (c *FakeClock) Timers() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil 阻塞直到等待中的定时器数不少于 n
+func (c *FakeClock) BlockUntil(n int) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for len(c.timers) < n {
+		c.cond.Wait()
+	}
+}
+
+// fire 更新时间并按到期顺序触发定时器 (需持有锁)
+func (c *FakeClock) fire(now time.Time) {
+	c.now = now
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].when.Before(c.timers[j].when)
+	})
+	rest := c.timers[:0]
+	for _, t := range c.timers {
+		if t.when.After(now) {
+			rest = append(rest, t)
+			continue
+		}
+		t.c <- now
+	}
+	c.timers = rest
+	c.cond.Broadcast()
+}
+
+// remove 移除定时器 (需持有锁)
+func (c *FakeClock) remove(t *fakeTimer) bool {
+	for i, e := range c.timers {
+		if e == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.cond.Broadcast()
+			return true
+		}
+	}
+	return false
+}
+
+// fakeTimer 模拟定时器
+type fakeTimer struct {
+	clock *FakeClock
+	when  time.Time
+	c     chan time.Time
+}
+
+// C 到期信号
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop 停止定时器
+func (t *fakeTimer) Stop() bool {
+	t.clock.lock.Lock()
+	defer t.clock.lock.Unlock()
+	return t.clock.remove(t)
+}
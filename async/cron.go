@@ -0,0 +1,303 @@
+package async
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCron 非法 cron 表达式
+var ErrInvalidCron = errors.New("async: invalid cron expression")
+
+// Schedule 调度计划
+type Schedule interface {
+	// Next 返回 prev 之后的下一次执行时间 (零值表示不再执行)
+	Next(prev time.Time) time.Time
+}
+
+// cronField cron 字段定义
+type cronField struct {
+	name     string         // 字段名
+	min, max int            // 取值范围
+	names    map[string]int // 别名
+}
+
+var (
+	secondField = cronField{name: "second", min: 0, max: 59}
+	minuteField = cronField{name: "minute", min: 0, max: 59}
+	hourField   = cronField{name: "hour", min: 0, max: 23}
+	domField    = cronField{name: "day of month", min: 1, max: 31}
+	monthField  = cronField{name: "month", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{name: "day of week", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+// cronDescriptors 预定义表达式
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 0 1 1 *",
+	"@annually": "0 0 0 1 1 *",
+	"@monthly":  "0 0 0 1 * *",
+	"@weekly":   "0 0 0 * * 0",
+	"@daily":    "0 0 0 * * *",
+	"@midnight": "0 0 0 * * *",
+	"@hourly":   "0 0 * * * *",
+}
+
+// CronSchedule cron 调度计划 (位图表示各字段取值)
+type CronSchedule struct {
+	Expr                           string         // 原始表达式
+	Location                       *time.Location // 时区
+	second, minute, hour, dom, dow uint64         // 字段位图
+	month                          uint64         // 月份位图
+	domStar, dowStar               bool           // 日/周字段是否为 *
+}
+
+// ParseCron 解析 cron 表达式
+// 支持 5 字段 (分 时 日 月 周) 及 6 字段 (秒 分 时 日 月 周),
+// 字段语法: * ? a a-b */n a-b/n a/n 及逗号列表, 月份与星期支持英文缩写 (JAN, MON),
+// 支持 @yearly/@monthly/@weekly/@daily/@hourly, 支持 CRON_TZ=Asia/Shanghai 或 TZ= 前缀指定时区 (默认 time.Local)
+func ParseCron(expr string) (*CronSchedule, error) {
+	return ParseCronInLocation(expr, time.Local)
+}
+
+// ParseCronInLocation 按指定时区解析 cron 表达式 (表达式中的时区前缀优先)
+func ParseCronInLocation(expr string, loc *time.Location) (*CronSchedule, error) {
+	s := &CronSchedule{Expr: expr, Location: loc}
+	spec := strings.TrimSpace(expr)
+	if strings.HasPrefix(spec, "CRON_TZ=") || strings.HasPrefix(spec, "TZ=") {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("%w %q: missing fields", ErrInvalidCron, expr)
+		}
+		name := spec[strings.Index(spec, "=")+1 : i]
+		l, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidCron, expr, err)
+		}
+		s.Location, spec = l, strings.TrimSpace(spec[i:])
+	}
+	if s.Location == nil {
+		s.Location = time.Local
+	}
+	if d, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+	fields := strings.Fields(spec)
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("%w %q: expected 5 or 6 fields, got %d", ErrInvalidCron, expr, len(fields))
+	}
+	var err error
+	parsers := []struct {
+		field cronField
+		bits  *uint64
+	}{
+		{secondField, &s.second}, {minuteField, &s.minute}, {hourField, &s.hour},
+		{domField, &s.dom}, {monthField, &s.month}, {dowField, &s.dow},
+	}
+	for i, p := range parsers {
+		if *p.bits, err = p.field.parse(fields[i]); err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidCron, expr, err)
+		}
+	}
+	// 星期 7 等同于 0 (周日)
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	s.domStar, s.dowStar = isStar(fields[3]), isStar(fields[5])
+	return s, nil
+}
+
+// MustParseCron 解析 cron 表达式 (非法时 panic)
+func MustParseCron(expr string) *CronSchedule {
+	s, err := ParseCron(expr)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// isStar 字段是否为不限制 (* 或 ?)
+func isStar(field string) bool {
+	return field == "*" || field == "?"
+}
+
+// parse 解析字段为位图
+func (f cronField) parse(field string) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		b, err := f.parseItem(item)
+		if err != nil {
+			return 0, err
+		}
+		bits |= b
+	}
+	return bits, nil
+}
+
+// parseItem 解析字段中的单项 (a, a-b, */n, a-b/n, a/n)
+func (f cronField) parseItem(item string) (uint64, error) {
+	rangePart, step := item, 1
+	if i := strings.Index(item, "/"); i >= 0 {
+		n, err := strconv.Atoi(item[i+1:])
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid step %q in %s field", item, f.name)
+		}
+		rangePart, step = item[:i], n
+	}
+	start, end := f.min, f.max
+	switch {
+	case isStar(rangePart):
+	case strings.Contains(rangePart, "-"):
+		i := strings.Index(rangePart, "-")
+		var err error
+		if start, err = f.value(rangePart[:i]); err != nil {
+			return 0, err
+		}
+		if end, err = f.value(rangePart[i+1:]); err != nil {
+			return 0, err
+		}
+		if start > end {
+			return 0, fmt.Errorf("invalid range %q in %s field", item, f.name)
+		}
+	default:
+		v, err := f.value(rangePart)
+		if err != nil {
+			return 0, err
+		}
+		start = v
+		// 单值且无步长时只匹配该值
+		if step == 1 && !strings.Contains(item, "/") {
+			end = v
+		}
+	}
+	var bits uint64
+	for v := start; v <= end; v += step {
+		bits |= 1 << uint(v)
+	}
+	return bits, nil
+}
+
+// value 解析字段值 (数字或别名)
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q in %s field", s, f.name)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d] in %s field", v, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+// Next 返回 prev 之后的下一次执行时间 (5 年内无匹配时返回零值)
+func (s *CronSchedule) Next(prev time.Time) time.Time {
+	origin := prev.Location()
+	t := prev.In(s.Location).Truncate(time.Second).Add(time.Second)
+	yearLimit := t.Year() + 5
+	truncated := false
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+	for s.month&(1<<uint(t.Month())) == 0 {
+		if !truncated {
+			truncated = true
+			t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.Location)
+		}
+		t = t.AddDate(0, 1, 0)
+		if t.Month() == time.January {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(t) {
+		if !truncated {
+			truncated = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.Location)
+		}
+		t = t.AddDate(0, 0, 1)
+		if t.Day() == 1 {
+			goto wrap
+		}
+	}
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		if !truncated {
+			truncated = true
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.Location)
+		}
+		t = t.Add(time.Hour)
+		if t.Hour() == 0 {
+			goto wrap
+		}
+	}
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		if !truncated {
+			truncated = true
+			t = t.Truncate(time.Minute)
+		}
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	for s.second&(1<<uint(t.Second())) == 0 {
+		truncated = true
+		t = t.Add(time.Second)
+		if t.Second() == 0 {
+			goto wrap
+		}
+	}
+	return t.In(origin)
+}
+
+// dayMatches 日期是否匹配 (日与星期均有限制时满足其一即可)
+func (s *CronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
+
+// ================================ 固定间隔 ================================= //
+
+// rateSchedule 固定频率调度计划
+type rateSchedule struct {
+	interval time.Duration
+}
+
+// Every 固定频率调度计划 (按上次计划时间累加间隔, 不受执行耗时影响)
+func Every(interval time.Duration) Schedule {
+	return &rateSchedule{interval: interval}
+}
+
+// Next 下一次执行时间
+func (s *rateSchedule) Next(prev time.Time) time.Time {
+	return prev.Add(s.interval)
+}
+
+// onceSchedule 单次调度计划
+type onceSchedule struct {
+	at time.Time
+}
+
+// Next 下一次执行时间 (只执行一次)
+func (s *onceSchedule) Next(prev time.Time) time.Time {
+	if prev.Before(s.at) {
+		return s.at
+	}
+	return time.Time{}
+}
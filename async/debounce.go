@@ -0,0 +1,200 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Edge 触发边沿
+type Edge int
+
+const (
+	EdgeDefault  Edge = iota // 默认 (Debounce 为 EdgeTrailing, Throttle 为 EdgeBoth)
+	EdgeLeading              // 前沿触发 (窗口开始时执行第一次调用)
+	EdgeTrailing             // 后沿触发 (窗口结束时执行最后一次调用)
+)
+
+// EdgeBoth 前后沿均触发
+const EdgeBoth = EdgeLeading | EdgeTrailing
+
+// DebounceConfig Debounce/Throttle 配置
+type DebounceConfig struct {
+	Edge    Edge          // 触发边沿
+	MaxWait time.Duration // 最长等待时间 (仅 Debounce, 持续调用时最迟在首次调用后 MaxWait 执行, 0 不限制)
+	Clock   Clock         // 时钟 (默认 RealClock)
+}
+
+// Debouncer 防抖调用器 (连续调用停止 wait 后才执行)
+type Debouncer[T any] struct {
+	*edgeCaller[T]
+}
+
+// Throttler 节流调用器 (每 interval 最多执行一次)
+type Throttler[T any] struct {
+	*edgeCaller[T]
+}
+
+// Debounce 新建防抖调用器 (ctx 取消后丢弃等待中的调用并忽略后续调用)
+func Debounce[T any](ctx context.Context, wait time.Duration, fn func(ctx context.Context, v T), conf *DebounceConfig) *Debouncer[T] {
+	return &Debouncer[T]{newEdgeCaller(ctx, wait, fn, conf, EdgeTrailing, false)}
+}
+
+// Throttle 新建节流调用器 (ctx 取消后丢弃等待中的调用并忽略后续调用)
+func Throttle[T any](ctx context.Context, interval time.Duration, fn func(ctx context.Context, v T), conf *DebounceConfig) *Throttler[T] {
+	return &Throttler[T]{newEdgeCaller(ctx, interval, fn, conf, EdgeBoth, true)}
+}
+
+// edgeCaller 防抖/节流调用器实现
+type edgeCaller[T any] struct {
+	ctx      context.Context                // 上下文
+	fn       func(ctx context.Context, v T) // 执行方法
+	wait     time.Duration                  // 等待时间/节流间隔
+	conf     *DebounceConfig                // 配置
+	throttle bool                           // 是否为节流模式
+	lock     sync.Mutex                     // 状态锁
+	active   bool                           // 是否处于窗口中
+	pending  bool                           // 是否有等待执行的调用
+	arg      T                              // 等待执行的调用参数
+	first    time.Time                      // 窗口首次调用时间
+	gen      uint64                         // 定时器代数 (过期定时器触发时忽略)
+	stop     chan struct{}                  // 停止当前定时器
+}
+
+// newEdgeCaller 新建调用器
+func newEdgeCaller[T any](ctx context.Context, wait time.Duration, fn func(ctx context.Context, v T), conf *DebounceConfig, edge Edge, throttle bool) *edgeCaller[T] {
+	if conf == nil {
+		conf = &DebounceConfig{}
+	}
+	if conf.Edge == EdgeDefault {
+		conf.Edge = edge
+	}
+	if conf.Clock == nil {
+		conf.Clock = RealClock
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &edgeCaller[T]{ctx: ctx, fn: fn, wait: wait, conf: conf, throttle: throttle}
+}
+
+// Call 调用
+func (c *edgeCaller[T]) Call(v T) {
+	c.lock.Lock()
+	if c.ctx.Err() != nil {
+		c.lock.Unlock()
+		return
+	}
+	now := c.conf.Clock.Now()
+	if !c.active {
+		c.active, c.first = true, now
+		c.arm(now.Add(c.wait))
+		if c.conf.Edge&EdgeLeading != 0 {
+			c.lock.Unlock()
+			c.fn(c.ctx, v)
+			return
+		}
+		c.pending, c.arg = true, v
+		c.lock.Unlock()
+		return
+	}
+	if c.conf.Edge&EdgeTrailing != 0 {
+		c.pending, c.arg = true, v
+	}
+	// 防抖: 每次调用重新计时 (不超过 MaxWait)
+	if !c.throttle {
+		deadline := now.Add(c.wait)
+		if c.conf.MaxWait > 0 && c.first.Add(c.conf.MaxWait).Before(deadline) {
+			deadline = c.first.Add(c.conf.MaxWait)
+		}
+		c.arm(deadline)
+	}
+	c.lock.Unlock()
+}
+
+// Flush 立即执行等待中的调用 (若有)
+func (c *edgeCaller[T]) Flush() {
+	c.lock.Lock()
+	if !c.pending || c.ctx.Err() != nil {
+		c.lock.Unlock()
+		return
+	}
+	v := c.arg
+	c.reset()
+	c.lock.Unlock()
+	c.fn(c.ctx, v)
+}
+
+// Cancel 丢弃等待中的调用
+func (c *edgeCaller[T]) Cancel() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.reset()
+}
+
+// Pending 是否有等待执行的调用
+func (c *edgeCaller[T]) Pending() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.pending
+}
+
+// reset 结束窗口并停止定时器 (需持有锁)
+func (c *edgeCaller[T]) reset() {
+	var zero T
+	c.active, c.pending, c.arg = false, false, zero
+	c.gen++
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+}
+
+// arm 重新设置定时器 (需持有锁)
+func (c *edgeCaller[T]) arm(deadline time.Time) {
+	if c.stop != nil {
+		close(c.stop)
+	}
+	c.gen++
+	gen, stop := c.gen, make(chan struct{})
+	c.stop = stop
+	timer := c.conf.Clock.NewTimer(deadline.Sub(c.conf.Clock.Now()))
+	go func() {
+		select {
+		case <-timer.C():
+			c.fire(gen)
+		case <-stop:
+			timer.Stop()
+		case <-c.ctx.Done():
+			timer.Stop()
+			c.Cancel()
+		}
+	}()
+}
+
+// fire 窗口结束 (后沿触发)
+func (c *edgeCaller[T]) fire(gen uint64) {
+	c.lock.Lock()
+	if gen != c.gen || c.ctx.Err() != nil {
+		c.lock.Unlock()
+		return
+	}
+	c.stop = nil
+	if !c.pending {
+		c.active = false
+		c.lock.Unlock()
+		return
+	}
+	v := c.arg
+	var zero T
+	c.pending, c.arg = false, zero
+	if c.throttle {
+		// 节流: 后沿执行后开启新窗口, 保证执行间隔
+		c.first = c.conf.Clock.Now()
+		c.arm(c.first.Add(c.wait))
+	} else {
+		c.active = false
+	}
+	c.lock.Unlock()
+	c.fn(c.ctx, v)
+}
@@ -0,0 +1,283 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// ErrNoFuture 未传入任何 Future
+var ErrNoFuture = errors.New("async: no future given")
+
+// AggregateError 聚合错误 (Any 全部失败时返回)
+type AggregateError struct {
+	Errors []error // 错误列表 (按 Future 顺序)
+}
+
+func (err *AggregateError) Error() string {
+	msgs := make([]string, 0, len(err.Errors))
+	for _, e := range err.Errors {
+		msgs = append(msgs, e.Error())
+	}
+	return fmt.Sprintf("all futures failed: [%s]", strings.Join(msgs, "; "))
+}
+
+// Unwrap 获取错误列表
+func (err *AggregateError) Unwrap() []error {
+	return err.Errors
+}
+
+// Future 类型安全的异步结果
+type Future[T any] struct {
+	done  chan struct{} // 完成信号
+	once  sync.Once     // 保证只完成一次
+	value T             // 结果
+	err   error         // 错误
+}
+
+// Settled 已完成的 Future 结果 (AllSettled 使用)
+type Settled[T any] struct {
+	Value T     // 结果
+	Err   error // 错误
+}
+
+// newFuture 新建未完成的 Future
+func newFuture[T any]() *Future[T] {
+	return &Future[T]{done: make(chan struct{})}
+}
+
+// complete 完成 Future (只有第一次调用生效)
+func (f *Future[T]) complete(value T, err error) bool {
+	completed := false
+	f.once.Do(func() {
+		f.value, f.err = value, err
+		close(f.done)
+		completed = true
+	})
+	return completed
+}
+
+// run 执行方法并完成 Future (panic 转换为 PanicError)
+func (f *Future[T]) run(fn func() (T, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			var zero T
+			f.complete(zero, &PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+	f.complete(fn())
+}
+
+// Go 异步执行方法, 返回 Future
+func Go[T any](fn func() (T, error)) *Future[T] {
+	f := newFuture[T]()
+	go f.run(fn)
+	return f
+}
+
+// Submit 提交方法到协程池执行, 返回 Future
+func Submit[T any](pool *GoPool, fn func() (T, error)) (*Future[T], error) {
+	f := newFuture[T]()
+	if err := pool.Go(func() { f.run(fn) }); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Completed 新建已成功完成的 Future
+func Completed[T any](value T) *Future[T] {
+	f := newFuture[T]()
+	f.complete(value, nil)
+	return f
+}
+
+// Failed 新建已失败的 Future
+func Failed[T any](err error) *Future[T] {
+	f := newFuture[T]()
+	var zero T
+	f.complete(zero, err)
+	return f
+}
+
+// Done 完成信号
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await 同步等待结果
+func (f *Future[T]) Await() (T, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// AwaitCtx 同步等待结果 (上下文取消时返回 ctx.Err())
+func (f *Future[T]) AwaitCtx(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// Map 结果转换 (失败时直接传递错误)
+func Map[T, R any](f *Future[T], fn func(T) (R, error)) *Future[R] {
+	return Go(func() (R, error) {
+		v, err := f.Await()
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return fn(v)
+	})
+}
+
+// FlatMap 结果转换为新的 Future (失败时直接传递错误)
+func FlatMap[T, R any](f *Future[T], fn func(T) *Future[R]) *Future[R] {
+	return Go(func() (R, error) {
+		v, err := f.Await()
+		if err != nil {
+			var zero R
+			return zero, err
+		}
+		return fn(v).Await()
+	})
+}
+
+// All 等待全部成功 (按顺序返回结果, 任一失败时立即失败)
+func All[T any](fs ...*Future[T]) *Future[[]T] {
+	res := newFuture[[]T]()
+	values := make([]T, len(fs))
+	var (
+		wg   sync.WaitGroup
+		lock sync.Mutex
+	)
+	wg.Add(len(fs))
+	for i, f := range fs {
+		go func(i int, f *Future[T]) {
+			defer wg.Done()
+			v, err := f.Await()
+			if err != nil {
+				res.complete(nil, err)
+				return
+			}
+			lock.Lock()
+			values[i] = v
+			lock.Unlock()
+		}(i, f)
+	}
+	go func() {
+		wg.Wait()
+		lock.Lock()
+		defer lock.Unlock()
+		res.complete(values, nil)
+	}()
+	return res
+}
+
+// Any 返回第一个成功的结果 (全部失败时返回 AggregateError)
+func Any[T any](fs ...*Future[T]) *Future[T] {
+	res := newFuture[T]()
+	if len(fs) == 0 {
+		var zero T
+		res.complete(zero, ErrNoFuture)
+		return res
+	}
+	errs := make([]error, len(fs))
+	var wg sync.WaitGroup
+	wg.Add(len(fs))
+	for i, f := range fs {
+		go func(i int, f *Future[T]) {
+			defer wg.Done()
+			v, err := f.Await()
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			res.complete(v, nil)
+		}(i, f)
+	}
+	go func() {
+		wg.Wait()
+		var zero T
+		res.complete(zero, &AggregateError{Errors: errs})
+	}()
+	return res
+}
+
+// Race 返回第一个完成的结果 (无论成功失败)
+func Race[T any](fs ...*Future[T]) *Future[T] {
+	res := newFuture[T]()
+	if len(fs) == 0 {
+		var zero T
+		res.complete(zero, ErrNoFuture)
+		return res
+	}
+	for _, f := range fs {
+		go func(f *Future[T]) {
+			res.complete(f.Await())
+		}(f)
+	}
+	return res
+}
+
+// AllSettled 等待全部完成 (按顺序返回每个 Future 的结果与错误)
+func AllSettled[T any](fs ...*Future[T]) *Future[[]Settled[T]] {
+	return Go(func() ([]Settled[T], error) {
+		res := make([]Settled[T], len(fs))
+		for i, f := range fs {
+			res[i].Value, res[i].Err = f.Await()
+		}
+		return res, nil
+	})
+}
+
+// ============================ Promise 互操作 =============================== //
+
+// Action 转换为 Promise Action (等待 Future 结果作为该阶段结果)
+func (f *Future[T]) Action() Action {
+	return Action{Fn: f.Await}
+}
+
+// Typed 类型安全的 Promise 阶段 (上一步第一个结果断言为 T, 类型不符时返回错误而不是 panic)
+func Typed[T, R any](fn func(T) (R, error)) Action {
+	return Action{
+		Fn: func(prev ...any) (R, error) {
+			var (
+				zero R
+				v    T
+			)
+			if len(prev) > 0 && prev[0] != nil {
+				tv, ok := prev[0].(T)
+				if !ok {
+					return zero, fmt.Errorf("async: expected %T, got %T", v, prev[0])
+				}
+				v = tv
+			}
+			return fn(v)
+		},
+	}
+}
+
+// FromPromise 将 Promise 转换为 Future (取第一个结果并断言为 T)
+func FromPromise[T any](p *Promise) *Future[T] {
+	return Go(func() (T, error) {
+		var zero T
+		res, err := p.Get()
+		if err != nil {
+			return zero, err
+		}
+		if len(res) == 0 || res[0] == nil {
+			return zero, nil
+		}
+		v, ok := res[0].(T)
+		if !ok {
+			return zero, fmt.Errorf("async: expected %T, got %T", zero, res[0])
+		}
+		return v, nil
+	})
+}
@@ -1 +1,357 @@
 package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/base"
+)
+
+var (
+	ErrPoolClosed = errors.New("async: pool is closed")       // 协程池已关闭
+	ErrPoolFull   = errors.New("async: pool queue is full")   // 协程池任务队列已满
+	ErrNilTask    = errors.New("async: task function is nil") // 任务方法为空
+)
+
+// PanicError 协程 panic 错误 (携带 panic 值及堆栈)
+type PanicError struct {
+	Value any    // panic 值
+	Stack []byte // 堆栈信息
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", err.Value, err.Stack)
+}
+
+// Unwrap panic 值为 error 时返回该错误
+func (err *PanicError) Unwrap() error {
+	if e, ok := err.Value.(error); ok {
+		return e
+	}
+	return nil
+}
+
+// TaskFn 协程池任务方法
+type TaskFn func() (any, error)
+
+// TaskFuture 协程池任务结果
+type TaskFuture struct {
+	done  chan struct{} // 任务完成信号
+	value any           // 任务返回值
+	err   error         // 任务错误
+}
+
+// Done 任务完成信号
+func (f *TaskFuture) Done() <-chan struct{} {
+	return f.done
+}
+
+// Get 同步等待获取任务结果
+func (f *TaskFuture) Get() (any, error) {
+	<-f.done
+	return f.value, f.err
+}
+
+// GetContext 同步等待获取任务结果 (上下文取消时返回)
+func (f *TaskFuture) GetContext(ctx context.Context) (any, error) {
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// poolTask 协程池任务
+type poolTask struct {
+	fn     TaskFn      // 任务方法
+	future *TaskFuture // 任务结果
+}
+
+// GoPoolConfig GoPool 配置
+type GoPoolConfig struct {
+	Capacity     int                   // 最大工作协程数 (默认 runtime.NumCPU())
+	QueueSize    int                   // 任务队列大小 (默认 Capacity * 64)
+	IdleTimeout  time.Duration         // 空闲工作协程回收时间 (默认 10s)
+	PanicHandler func(err *PanicError) // panic 处理钩子
+}
+
+// PoolStats 协程池统计信息
+type PoolStats struct {
+	Capacity  int   // 最大工作协程数
+	Workers   int   // 当前工作协程数
+	Idle      int   // 空闲工作协程数
+	Running   int64 // 执行中任务数
+	Queued    int   // 排队中任务数
+	Completed int64 // 已完成任务数 (含失败)
+	Failed    int64 // 失败任务数 (含 panic)
+	Panicked  int64 // panic 任务数
+}
+
+// GoPool 协程池
+type GoPool struct {
+	conf       *GoPoolConfig  // 配置
+	tasks      chan *poolTask // 任务队列
+	shrink     chan struct{}  // 缩容信号
+	closing    chan struct{}  // 关闭信号
+	terminated chan struct{}  // 终止信号 (所有工作协程退出)
+	closeOnce  sync.Once      // 关闭操作只执行一次
+	lock       sync.Mutex     // 工作协程状态锁
+	capacity   int            // 最大工作协程数
+	workers    int            // 当前工作协程数
+	idle       int            // 空闲工作协程数
+	closed     bool           // 是否已关闭
+	submitting sync.WaitGroup // 提交中任务计数
+	wg         sync.WaitGroup // 工作协程计数
+	running    int64          // 执行中任务数
+	completed  int64          // 已完成任务数
+	failed     int64          // 失败任务数
+	panicked   int64          // panic 任务数
+}
+
+// NewGoPool 新建协程池 (conf 为 nil 时使用默认配置)
+func NewGoPool(conf *GoPoolConfig) *GoPool {
+	if conf == nil {
+		conf = &GoPoolConfig{}
+	}
+	conf.Capacity = base.SetOrDefault(conf.Capacity, runtime.NumCPU())
+	conf.QueueSize = base.SetOrDefault(conf.QueueSize, conf.Capacity*64)
+	conf.IdleTimeout = base.SetOrDefault(conf.IdleTimeout, 10*time.Second)
+	return &GoPool{
+		conf:       conf,
+		tasks:      make(chan *poolTask, conf.QueueSize),
+		shrink:     make(chan struct{}, conf.Capacity),
+		closing:    make(chan struct{}),
+		terminated: make(chan struct{}),
+		capacity:   conf.Capacity,
+	}
+}
+
+// Submit 提交任务 (任务队列已满时阻塞等待)
+func (p *GoPool) Submit(fn TaskFn) (*TaskFuture, error) {
+	return p.SubmitContext(context.Background(), fn)
+}
+
+// SubmitContext 提交任务 (任务队列已满时阻塞等待, 上下文取消时返回)
+func (p *GoPool) SubmitContext(ctx context.Context, fn TaskFn) (*TaskFuture, error) {
+	t, err := p.begin(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer p.submitting.Done()
+	select {
+	case p.tasks <- t:
+	case <-p.closing:
+		return nil, ErrPoolClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	p.ensureWorker()
+	return t.future, nil
+}
+
+// TrySubmit 尝试提交任务 (任务队列已满时返回 ErrPoolFull)
+func (p *GoPool) TrySubmit(fn TaskFn) (*TaskFuture, error) {
+	t, err := p.begin(fn)
+	if err != nil {
+		return nil, err
+	}
+	defer p.submitting.Done()
+	select {
+	case p.tasks <- t:
+	default:
+		return nil, ErrPoolFull
+	}
+	p.ensureWorker()
+	return t.future, nil
+}
+
+// Go 提交无返回值任务 (任务队列已满时阻塞等待)
+func (p *GoPool) Go(fn func()) error {
+	if fn == nil {
+		return ErrNilTask
+	}
+	_, err := p.Submit(func() (any, error) {
+		fn()
+		return nil, nil
+	})
+	return err
+}
+
+// begin 登记任务提交
+func (p *GoPool) begin(fn TaskFn) (*poolTask, error) {
+	if fn == nil {
+		return nil, ErrNilTask
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	p.submitting.Add(1)
+	return &poolTask{fn: fn, future: &TaskFuture{done: make(chan struct{})}}, nil
+}
+
+// ensureWorker 无空闲工作协程且未达上限时新建工作协程
+func (p *GoPool) ensureWorker() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.idle == 0 && p.workers < p.capacity {
+		p.spawn()
+	}
+}
+
+// spawn 新建工作协程 (需持有锁)
+func (p *GoPool) spawn() {
+	p.workers++
+	p.wg.Add(1)
+	go p.work()
+}
+
+// work 工作协程主循环
+func (p *GoPool) work() {
+	defer p.wg.Done()
+	timer := time.NewTimer(p.conf.IdleTimeout)
+	defer timer.Stop()
+	for {
+		p.lock.Lock()
+		// 缩容: 超出容量的工作协程退出
+		if p.workers > p.capacity {
+			p.workers--
+			p.lock.Unlock()
+			return
+		}
+		p.idle++
+		p.lock.Unlock()
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(p.conf.IdleTimeout)
+		select {
+		case t, ok := <-p.tasks:
+			p.lock.Lock()
+			p.idle--
+			if !ok {
+				p.workers--
+				p.lock.Unlock()
+				return
+			}
+			p.lock.Unlock()
+			p.execute(t)
+		case <-p.shrink:
+			p.lock.Lock()
+			p.idle--
+			p.lock.Unlock()
+		case <-timer.C:
+			p.lock.Lock()
+			p.idle--
+			// 存在排队任务时不回收, 避免任务滞留
+			if len(p.tasks) > 0 {
+				p.lock.Unlock()
+				continue
+			}
+			p.workers--
+			p.lock.Unlock()
+			return
+		}
+	}
+}
+
+// execute 执行任务 (panic 恢复)
+func (p *GoPool) execute(t *poolTask) {
+	atomic.AddInt64(&p.running, 1)
+	defer func() {
+		if r := recover(); r != nil {
+			pe := &PanicError{Value: r, Stack: debug.Stack()}
+			t.future.err = pe
+			atomic.AddInt64(&p.panicked, 1)
+			if p.conf.PanicHandler != nil {
+				p.conf.PanicHandler(pe)
+			}
+		}
+		if t.future.err != nil {
+			atomic.AddInt64(&p.failed, 1)
+		}
+		atomic.AddInt64(&p.running, -1)
+		atomic.AddInt64(&p.completed, 1)
+		close(t.future.done)
+	}()
+	t.future.value, t.future.err = t.fn()
+}
+
+// Resize 调整最大工作协程数 (缩容时空闲协程立即退出, 执行中协程完成当前任务后退出)
+func (p *GoPool) Resize(capacity int) {
+	if capacity <= 0 {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.capacity = capacity
+	for excess := p.workers - capacity; excess > 0; excess-- {
+		select {
+		case p.shrink <- struct{}{}:
+		default:
+		}
+	}
+	if p.closed {
+		return
+	}
+	// 扩容: 按排队任务数补充工作协程
+	for queued := len(p.tasks) - p.idle; queued > 0 && p.workers < p.capacity; queued-- {
+		p.spawn()
+	}
+}
+
+// Stats 获取统计信息
+func (p *GoPool) Stats() PoolStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return PoolStats{
+		Capacity:  p.capacity,
+		Workers:   p.workers,
+		Idle:      p.idle,
+		Running:   atomic.LoadInt64(&p.running),
+		Queued:    len(p.tasks),
+		Completed: atomic.LoadInt64(&p.completed),
+		Failed:    atomic.LoadInt64(&p.failed),
+		Panicked:  atomic.LoadInt64(&p.panicked),
+	}
+}
+
+// Shutdown 优雅关闭 (拒绝新任务, 等待排队任务执行完毕; 上下文取消时返回 ctx.Err())
+func (p *GoPool) Shutdown(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		p.lock.Lock()
+		p.closed = true
+		close(p.closing)
+		p.lock.Unlock()
+		// 等待提交中任务返回后关闭任务队列
+		p.submitting.Wait()
+		p.lock.Lock()
+		close(p.tasks)
+		// 补充工作协程, 保证排队任务被执行
+		for queued := len(p.tasks) - p.idle; queued > 0 && p.workers < p.capacity; queued-- {
+			p.spawn()
+		}
+		p.lock.Unlock()
+		go func() {
+			p.wg.Wait()
+			close(p.terminated)
+		}()
+	})
+	select {
+	case <-p.terminated:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
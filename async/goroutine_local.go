@@ -0,0 +1,135 @@
+package async
+
+import "sync"
+
+// goLocalStore 协程本地存储表 map[go routine id]map[*goLocalKey]any
+// 每个协程只读写自身的存储 map, 因此 map 本身无需加锁
+var goLocalStore sync.Map
+
+// goLocalKey 协程本地变量键
+type goLocalKey struct {
+	inheritable bool // 是否可被子协程继承
+}
+
+// GoLocal 协程本地变量 (基于 GetGoRoutineID)
+// 注意: 协程退出时不会自动清理, 需通过 GoWithLocals/WrapWithLocals 启动协程, 或手动调用 Remove/ClearGoLocals
+type GoLocal[T any] struct {
+	key *goLocalKey // 变量键
+}
+
+// NewGoLocal 新建协程本地变量 (不可继承)
+func NewGoLocal[T any]() *GoLocal[T] {
+	return &GoLocal[T]{key: &goLocalKey{}}
+}
+
+// NewInheritableGoLocal 新建可继承协程本地变量 (通过 GoWithLocals/WrapWithLocals 启动的协程继承父协程的值)
+func NewInheritableGoLocal[T any]() *GoLocal[T] {
+	return &GoLocal[T]{key: &goLocalKey{inheritable: true}}
+}
+
+// Set 设置当前协程的值
+func (l *GoLocal[T]) Set(value T) {
+	currentLocals(true)[l.key] = value
+}
+
+// Get 获取当前协程的值
+func (l *GoLocal[T]) Get() (T, bool) {
+	var zero T
+	locals := currentLocals(false)
+	if locals == nil {
+		return zero, false
+	}
+	v, ok := locals[l.key]
+	if !ok {
+		return zero, false
+	}
+	return v.(T), true
+}
+
+// GetOrDefault 获取当前协程的值, 不存在时返回默认值
+func (l *GoLocal[T]) GetOrDefault(defaultValue T) T {
+	if v, ok := l.Get(); ok {
+		return v
+	}
+	return defaultValue
+}
+
+// Remove 移除当前协程的值
+func (l *GoLocal[T]) Remove() {
+	locals := currentLocals(false)
+	if locals == nil {
+		return
+	}
+	delete(locals, l.key)
+	if len(locals) == 0 {
+		goLocalStore.Delete(GetGoRoutineID())
+	}
+}
+
+// ClearGoLocals 清除当前协程的全部本地变量
+func ClearGoLocals() {
+	goLocalStore.Delete(GetGoRoutineID())
+}
+
+// currentLocals 获取当前协程的存储 map
+// create: 不存在时是否新建
+func currentLocals(create bool) map[*goLocalKey]any {
+	gid := GetGoRoutineID()
+	if v, ok := goLocalStore.Load(gid); ok {
+		return v.(map[*goLocalKey]any)
+	}
+	if !create {
+		return nil
+	}
+	locals := make(map[*goLocalKey]any)
+	goLocalStore.Store(gid, locals)
+	return locals
+}
+
+// snapshotLocals 复制当前协程的可继承本地变量
+func snapshotLocals() map[*goLocalKey]any {
+	locals := currentLocals(false)
+	if len(locals) == 0 {
+		return nil
+	}
+	res := make(map[*goLocalKey]any)
+	for k, v := range locals {
+		if k.inheritable {
+			res[k] = v
+		}
+	}
+	return res
+}
+
+// WrapWithLocals 捕获当前协程的可继承本地变量, 返回在任意协程执行时恢复这些变量的方法
+// 方法执行结束后自动清理执行协程的本地变量, 适用于 GoPool 等复用协程的场景
+func WrapWithLocals(fn func()) func() {
+	snapshot := snapshotLocals()
+	return func() {
+		gid := GetGoRoutineID()
+		prev, hasPrev := goLocalStore.Load(gid)
+		if len(snapshot) > 0 {
+			locals := make(map[*goLocalKey]any, len(snapshot))
+			for k, v := range snapshot {
+				locals[k] = v
+			}
+			goLocalStore.Store(gid, locals)
+		} else {
+			goLocalStore.Delete(gid)
+		}
+		// 恢复执行协程原有的本地变量 (同步调用时不影响调用方)
+		defer func() {
+			if hasPrev {
+				goLocalStore.Store(gid, prev)
+			} else {
+				goLocalStore.Delete(gid)
+			}
+		}()
+		fn()
+	}
+}
+
+// GoWithLocals 启动继承当前协程可继承本地变量的协程, 协程退出时自动清理
+func GoWithLocals(fn func()) {
+	go WrapWithLocals(fn)()
+}
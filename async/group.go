@@ -0,0 +1,199 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"strings"
+	"sync"
+)
+
+// TaskGroupConfig TaskGroup 配置
+type TaskGroupConfig struct {
+	Limit           int  // 最大并发数 (<= 0 不限制)
+	ContinueOnError bool // 任务出错时不取消其他任务 (默认取消)
+}
+
+// TaskGroup 结构化并发任务组
+// 任务绑定父上下文, 并发受限, 全部错误通过 MultiError 聚合, Wait 返回后不会残留任何任务协程
+type TaskGroup struct {
+	ctx     context.Context    // 任务组上下文
+	cancel  context.CancelFunc // 取消任务组上下文
+	conf    *TaskGroupConfig   // 配置
+	sem     chan struct{}      // 并发信号量
+	wg      sync.WaitGroup     // 任务计数
+	lock    sync.Mutex         // 状态锁
+	errs    []error            // 错误列表
+	skipped bool               // 是否存在因上下文取消而未执行的任务
+	closed  bool               // Wait 是否已返回
+}
+
+// NewTaskGroup 新建任务组 (conf 为 nil 时使用默认配置), 返回任务组及其上下文
+func NewTaskGroup(ctx context.Context, conf *TaskGroupConfig) (*TaskGroup, context.Context) {
+	if conf == nil {
+		conf = &TaskGroupConfig{}
+	}
+	g := &TaskGroup{conf: conf}
+	g.ctx, g.cancel = context.WithCancel(ctx)
+	if conf.Limit > 0 {
+		g.sem = make(chan struct{}, conf.Limit)
+	}
+	return g, g.ctx
+}
+
+// Go 启动任务 (达到并发上限时阻塞; 上下文已取消时不再启动)
+// Wait 返回后调用会 panic
+func (g *TaskGroup) Go(fn func(ctx context.Context) error) {
+	g.add()
+	if g.ctx.Err() != nil {
+		g.skip()
+		return
+	}
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			g.skip()
+			return
+		}
+		// 获取信号量期间上下文已取消
+		if g.ctx.Err() != nil {
+			<-g.sem
+			g.skip()
+			return
+		}
+	}
+	go g.run(fn)
+}
+
+// TryGo 尝试启动任务 (达到并发上限或上下文已取消时返回 false)
+func (g *TaskGroup) TryGo(fn func(ctx context.Context) error) bool {
+	if g.ctx.Err() != nil {
+		return false
+	}
+	g.add()
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			g.wg.Done()
+			return false
+		}
+	}
+	go g.run(fn)
+	return true
+}
+
+// add 登记任务
+func (g *TaskGroup) add() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if g.closed {
+		panic("async: TaskGroup.Go called after Wait")
+	}
+	g.wg.Add(1)
+}
+
+// skip 登记未执行任务
+func (g *TaskGroup) skip() {
+	g.lock.Lock()
+	g.skipped = true
+	g.lock.Unlock()
+	g.wg.Done()
+}
+
+// run 执行任务 (panic 转换为 PanicError)
+func (g *TaskGroup) run(fn func(ctx context.Context) error) {
+	defer func() {
+		if g.sem != nil {
+			<-g.sem
+		}
+		g.wg.Done()
+	}()
+	if err := g.call(fn); err != nil {
+		g.lock.Lock()
+		g.errs = append(g.errs, err)
+		g.lock.Unlock()
+		if !g.conf.ContinueOnError {
+			g.cancel()
+		}
+	}
+}
+
+// call 调用任务方法
+func (g *TaskGroup) call(fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(g.ctx)
+}
+
+// Wait 等待全部任务结束, 返回聚合错误 (MultiError)
+func (g *TaskGroup) Wait() error {
+	g.wg.Wait()
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.closed = true
+	errs := g.errs
+	// 任务因父上下文取消而未执行, 且无其他错误时返回上下文错误
+	if g.skipped && len(errs) == 0 {
+		errs = append(errs, g.ctx.Err())
+	}
+	g.cancel()
+	return joinErrors(errs)
+}
+
+// MultiError 聚合错误 (支持 errors.Is / errors.As 逐个匹配)
+type MultiError struct {
+	Errors []error // 错误列表
+}
+
+// Error 实现 error 接口 (逐行输出)
+func (e *MultiError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap 错误列表
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// Is 任一错误匹配即为匹配
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As 按顺序匹配第一个可转换的错误
+func (e *MultiError) As(target any) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// joinErrors 聚合错误 (忽略 nil, 无错误时返回 nil)
+func joinErrors(errs []error) error {
+	res := make([]error, 0, len(errs))
+	for _, err := range errs {
+		if err != nil {
+			res = append(res, err)
+		}
+	}
+	if len(res) == 0 {
+		return nil
+	}
+	return &MultiError{Errors: res}
+}
@@ -1,31 +1,33 @@
 package lock
 
 import (
-	"runtime"
+	"context"
+	"sync"
 	"sync/atomic"
 )
 
 // CASSignal CAS 信号量
 type CASSignal struct {
-	state int64 // 信号量标记
+	state  int64         // 信号量标记
+	lock   sync.Mutex    // 通知锁
+	notify chan struct{} // 归零通知 (关闭即广播)
 }
 
 // Add 增加信号量
 func (s *CASSignal) Add(x int64) {
-	for !atomic.CompareAndSwapInt64(&s.state, s.state, s.state+x) {
+	if atomic.AddInt64(&s.state, x) <= 0 {
+		s.broadcast()
 	}
 }
 
 // Increase 自增信号量
 func (s *CASSignal) Increase() {
-	for !atomic.CompareAndSwapInt64(&s.state, s.state, s.state+1) {
-	}
+	s.Add(1)
 }
 
 // Done 减少信号量
 func (s *CASSignal) Done() {
-	for !atomic.CompareAndSwapInt64(&s.state, s.state, s.state-1) {
-	}
+	s.Add(-1)
 }
 
 // Status 读取信号量状态
@@ -33,9 +35,40 @@ func (s *CASSignal) Status() int64 {
 	return atomic.LoadInt64(&s.state)
 }
 
+// Wait 等待信号量归零 (挂起等待, 不自旋)
 func (s *CASSignal) Wait() {
-	for s.Status() > 0 {
-		runtime.Gosched()
+	_ = s.WaitContext(context.Background())
+}
+
+// WaitContext 等待信号量归零 (上下文取消时返回 ctx.Err())
+func (s *CASSignal) WaitContext(ctx context.Context) error {
+	for {
+		s.lock.Lock()
+		// 持有通知锁时检查, 避免错过归零通知
+		if s.Status() <= 0 {
+			s.lock.Unlock()
+			return nil
+		}
+		if s.notify == nil {
+			s.notify = make(chan struct{})
+		}
+		ch := s.notify
+		s.lock.Unlock()
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// broadcast 通知全部等待者
+func (s *CASSignal) broadcast() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.notify != nil {
+		close(s.notify)
+		s.notify = nil
 	}
 }
 
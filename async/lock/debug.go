@@ -0,0 +1,457 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/base"
+)
+
+// DebugEventKind 调试事件类型
+type DebugEventKind string
+
+const (
+	DebugDeadlock DebugEventKind = "deadlock"  // 疑似死锁 (等待图成环)
+	DebugLongHold DebugEventKind = "long_hold" // 长时间持有
+)
+
+// DebugConfig 锁调试配置
+type DebugConfig struct {
+	LongHoldThreshold time.Duration           // 长时间持有阈值 (默认 1s)
+	CheckInterval     time.Duration           // 长时间持有后台检查间隔 (默认 LongHoldThreshold / 2)
+	CaptureStack      bool                    // 是否记录获取锁及等待锁时的堆栈
+	Hook              func(event *DebugEvent) // 疑似死锁或长时间持有时的回调
+}
+
+// DebugEvent 调试事件
+type DebugEvent struct {
+	Kind  DebugEventKind `json:"kind"`            // 事件类型
+	Time  time.Time      `json:"time"`            // 事件时间
+	Lock  *LockInfo      `json:"lock,omitempty"`  // 相关锁 (长时间持有)
+	Cycle []WaitEdge     `json:"cycle,omitempty"` // 等待环 (疑似死锁)
+}
+
+// String 事件描述
+func (e *DebugEvent) String() string {
+	if e.Kind == DebugDeadlock {
+		return "suspected deadlock: " + formatCycle(e.Cycle)
+	}
+	return fmt.Sprintf("long hold: lock %s held by goroutine %d for %v", e.Lock.Name, e.Lock.Owner, e.Lock.HoldTime)
+}
+
+// WaitEdge 等待图边 (协程等待锁, 锁被另一协程持有)
+type WaitEdge struct {
+	Goroutine int32  `json:"goroutine"` // 等待协程
+	Lock      string `json:"lock"`      // 等待的锁
+	Owner     int32  `json:"owner"`     // 锁持有协程
+}
+
+// WaiterInfo 等待者信息
+type WaiterInfo struct {
+	Goroutine int32         `json:"goroutine"`       // 等待协程
+	Since     time.Time     `json:"since"`           // 开始等待时间
+	WaitTime  time.Duration `json:"wait_time"`       // 已等待时间
+	Stack     string        `json:"stack,omitempty"` // 等待堆栈
+}
+
+// LockInfo 锁调试信息
+type LockInfo struct {
+	Name         string        `json:"name"`                  // 锁名称
+	Owner        int32         `json:"owner"`                 // 持有协程 (0 表示未持有)
+	AcquiredAt   time.Time     `json:"acquired_at,omitempty"` // 获取时间
+	HoldTime     time.Duration `json:"hold_time"`             // 已持有时间
+	Stack        string        `json:"stack,omitempty"`       // 获取堆栈
+	Waiters      []WaiterInfo  `json:"waiters,omitempty"`     // 等待者
+	Acquisitions int64         `json:"acquisitions"`          // 获取次数
+	Contentions  int64         `json:"contentions"`           // 竞争次数 (需等待的获取)
+	TotalWait    time.Duration `json:"total_wait"`            // 累计等待时间
+	MaxWait      time.Duration `json:"max_wait"`              // 最长等待时间
+	TotalHold    time.Duration `json:"total_hold"`            // 累计持有时间
+	MaxHold      time.Duration `json:"max_hold"`              // 最长持有时间
+}
+
+// DebugReport 锁调试报告
+type DebugReport struct {
+	Time      time.Time    `json:"time"`      // 报告时间
+	Locks     []LockInfo   `json:"locks"`     // 锁信息 (按名称排序)
+	Deadlocks [][]WaitEdge `json:"deadlocks"` // 疑似死锁的等待环
+}
+
+// JSON 报告 JSON 格式
+func (r *DebugReport) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// String 报告文本格式
+func (r *DebugReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "=== lock debug report %s ===\n", r.Time.Format(time.RFC3339))
+	for _, l := range r.Locks {
+		fmt.Fprintf(&b, "lock %s: acquisitions=%d contentions=%d max_wait=%v max_hold=%v\n",
+			l.Name, l.Acquisitions, l.Contentions, l.MaxWait, l.MaxHold)
+		if l.Owner != 0 {
+			fmt.Fprintf(&b, "  held by goroutine %d for %v\n", l.Owner, l.HoldTime)
+			writeStack(&b, l.Stack)
+		}
+		for _, w := range l.Waiters {
+			fmt.Fprintf(&b, "  waited by goroutine %d for %v\n", w.Goroutine, w.WaitTime)
+			writeStack(&b, w.Stack)
+		}
+	}
+	for _, cycle := range r.Deadlocks {
+		fmt.Fprintf(&b, "suspected deadlock: %s\n", formatCycle(cycle))
+	}
+	return b.String()
+}
+
+// writeStack 缩进输出堆栈
+func writeStack(b *strings.Builder, stack string) {
+	for _, line := range strings.Split(strings.TrimSpace(stack), "\n") {
+		if line != "" {
+			fmt.Fprintf(b, "    %s\n", line)
+		}
+	}
+}
+
+// formatCycle 等待环描述
+func formatCycle(cycle []WaitEdge) string {
+	parts := make([]string, 0, len(cycle))
+	for _, e := range cycle {
+		parts = append(parts, fmt.Sprintf("goroutine %d waits %s (held by goroutine %d)", e.Goroutine, e.Lock, e.Owner))
+	}
+	return strings.Join(parts, " -> ")
+}
+
+// ================================ 调试器 ================================= //
+
+// lockWaitRecord 等待记录
+type lockWaitRecord struct {
+	gid   int32       // 等待协程
+	lock  *lockRecord // 等待的锁
+	since time.Time   // 开始等待时间
+	stack string      // 等待堆栈
+}
+
+// lockRecord 锁记录
+type lockRecord struct {
+	name         string                    // 锁名称
+	owner        int32                     // 持有协程
+	acquiredAt   time.Time                 // 获取时间
+	stack        string                    // 获取堆栈
+	reported     bool                      // 本次持有是否已报告长时间持有
+	waiters      map[int32]*lockWaitRecord // 等待者
+	acquisitions int64                     // 获取次数
+	contentions  int64                     // 竞争次数
+	totalWait    time.Duration             // 累计等待时间
+	maxWait      time.Duration             // 最长等待时间
+	totalHold    time.Duration             // 累计持有时间
+	maxHold      time.Duration             // 最长持有时间
+}
+
+// lockDebugger 锁调试器
+type lockDebugger struct {
+	conf    *DebugConfig                // 配置
+	lock    sync.Mutex                  // 状态锁
+	locks   map[*CustomLock]*lockRecord // 锁记录
+	waiting map[int32]*lockWaitRecord   // 协程 -> 等待记录 (等待图)
+	stop    chan struct{}               // 停止后台检查
+}
+
+var (
+	debugOn   int32         // 调试模式开关
+	debugger  *lockDebugger // 当前调试器
+	debugLock sync.Mutex    // 调试器切换锁
+	lockNames sync.Map      // 锁名称 map[*CustomLock]string
+)
+
+// EnableDebug 开启 CustomLock 调试模式 (conf 为 nil 时使用默认配置, 重复调用时替换配置并清空记录)
+func EnableDebug(conf *DebugConfig) {
+	if conf == nil {
+		conf = &DebugConfig{}
+	}
+	conf.LongHoldThreshold = base.SetOrDefault(conf.LongHoldThreshold, time.Second)
+	conf.CheckInterval = base.SetOrDefault(conf.CheckInterval, conf.LongHoldThreshold/2)
+	d := &lockDebugger{
+		conf:    conf,
+		locks:   make(map[*CustomLock]*lockRecord),
+		waiting: make(map[int32]*lockWaitRecord),
+		stop:    make(chan struct{}),
+	}
+	debugLock.Lock()
+	defer debugLock.Unlock()
+	if debugger != nil {
+		close(debugger.stop)
+	}
+	debugger = d
+	atomic.StoreInt32(&debugOn, 1)
+	go d.watch()
+}
+
+// DisableDebug 关闭 CustomLock 调试模式
+func DisableDebug() {
+	debugLock.Lock()
+	defer debugLock.Unlock()
+	atomic.StoreInt32(&debugOn, 0)
+	if debugger != nil {
+		close(debugger.stop)
+		debugger = nil
+	}
+}
+
+// DebugReportNow 生成当前调试报告 (未开启调试模式时返回空报告)
+func DebugReportNow() *DebugReport {
+	d := currentDebugger()
+	if d == nil {
+		return &DebugReport{Time: time.Now()}
+	}
+	return d.report()
+}
+
+// SetName 设置锁名称 (用于调试报告, 默认为锁地址)
+func (l *CustomLock) SetName(name string) {
+	lockNames.Store(l, name)
+}
+
+// debugEnabled 是否开启调试模式
+func debugEnabled() bool {
+	return atomic.LoadInt32(&debugOn) == 1
+}
+
+// currentDebugger 当前调试器
+func currentDebugger() *lockDebugger {
+	debugLock.Lock()
+	defer debugLock.Unlock()
+	return debugger
+}
+
+// lockDebug 调试模式上锁 (记录等待与持有信息)
+func (l *CustomLock) lockDebug(ctx context.Context, gid uint64) error {
+	if (!l.handoffMode() || atomic.LoadUint32(&l.waiter) == 0) && l.fastLock(gid) {
+		if d := currentDebugger(); d != nil {
+			d.acquired(l, gid, 0)
+		}
+		return nil
+	}
+	if l.isReentrant(gid) {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d := currentDebugger()
+	if d == nil {
+		return l.lockSlow(ctx, gid)
+	}
+	w := d.beginWait(l, gid)
+	err := l.lockSlow(ctx, gid)
+	d.endWait(w)
+	if err != nil {
+		return err
+	}
+	d.acquired(l, gid, time.Since(w.since))
+	return nil
+}
+
+// record 获取锁记录 (需持有状态锁)
+func (d *lockDebugger) record(l *CustomLock) *lockRecord {
+	r, ok := d.locks[l]
+	if !ok {
+		name := fmt.Sprintf("%p", l)
+		if v, ok := lockNames.Load(l); ok {
+			name = v.(string)
+		}
+		r = &lockRecord{name: name, waiters: make(map[int32]*lockWaitRecord)}
+		d.locks[l] = r
+	}
+	return r
+}
+
+// stack 按配置记录堆栈
+func (d *lockDebugger) stack() string {
+	if !d.conf.CaptureStack {
+		return ""
+	}
+	buf := make([]byte, 4096)
+	return string(buf[:runtime.Stack(buf, false)])
+}
+
+// beginWait 登记等待, 等待图成环时回调疑似死锁
+func (d *lockDebugger) beginWait(l *CustomLock, gid uint64) *lockWaitRecord {
+	w := &lockWaitRecord{gid: int32(gid >> ownerShift), since: time.Now(), stack: d.stack()}
+	d.lock.Lock()
+	w.lock = d.record(l)
+	w.lock.waiters[w.gid] = w
+	d.waiting[w.gid] = w
+	cycle := d.findCycle(w.gid)
+	d.lock.Unlock()
+	if cycle != nil {
+		d.emit(&DebugEvent{Kind: DebugDeadlock, Time: time.Now(), Cycle: cycle})
+	}
+	return w
+}
+
+// endWait 注销等待
+func (d *lockDebugger) endWait(w *lockWaitRecord) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(w.lock.waiters, w.gid)
+	if d.waiting[w.gid] == w {
+		delete(d.waiting, w.gid)
+	}
+}
+
+// acquired 记录获取锁
+func (d *lockDebugger) acquired(l *CustomLock, gid uint64, wait time.Duration) {
+	stack := d.stack()
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	r := d.record(l)
+	r.owner, r.acquiredAt, r.stack, r.reported = int32(gid>>ownerShift), time.Now(), stack, false
+	r.acquisitions++
+	if wait > 0 {
+		r.contentions++
+		r.totalWait += wait
+		if wait > r.maxWait {
+			r.maxWait = wait
+		}
+	}
+}
+
+// released 记录释放锁, 持有时间超过阈值且未报告时回调
+func (d *lockDebugger) released(l *CustomLock) {
+	d.lock.Lock()
+	r, ok := d.locks[l]
+	if !ok || r.acquiredAt.IsZero() {
+		d.lock.Unlock()
+		return
+	}
+	hold := time.Since(r.acquiredAt)
+	r.totalHold += hold
+	if hold > r.maxHold {
+		r.maxHold = hold
+	}
+	var event *DebugEvent
+	if hold > d.conf.LongHoldThreshold && !r.reported {
+		info := r.info(time.Now())
+		event = &DebugEvent{Kind: DebugLongHold, Time: time.Now(), Lock: &info}
+	}
+	r.owner, r.acquiredAt, r.stack = 0, time.Time{}, ""
+	d.lock.Unlock()
+	if event != nil {
+		d.emit(event)
+	}
+}
+
+// findCycle 从协程出发沿等待图查找环 (需持有状态锁)
+// 每个协程至多等待一个锁, 每个锁至多一个持有者, 因此沿边前进即可
+func (d *lockDebugger) findCycle(start int32) []WaitEdge {
+	var path []WaitEdge
+	visited := make(map[int32]bool)
+	for gid := start; ; {
+		w, ok := d.waiting[gid]
+		if !ok || w.lock.owner == 0 || visited[gid] {
+			return nil
+		}
+		visited[gid] = true
+		path = append(path, WaitEdge{Goroutine: gid, Lock: w.lock.name, Owner: w.lock.owner})
+		if w.lock.owner == start {
+			return path
+		}
+		gid = w.lock.owner
+	}
+}
+
+// watch 后台检查长时间持有
+func (d *lockDebugger) watch() {
+	ticker := time.NewTicker(d.conf.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+		}
+		now := time.Now()
+		var events []*DebugEvent
+		d.lock.Lock()
+		for _, r := range d.locks {
+			if r.owner == 0 || r.reported || now.Sub(r.acquiredAt) <= d.conf.LongHoldThreshold {
+				continue
+			}
+			r.reported = true
+			info := r.info(now)
+			events = append(events, &DebugEvent{Kind: DebugLongHold, Time: now, Lock: &info})
+		}
+		d.lock.Unlock()
+		for _, e := range events {
+			d.emit(e)
+		}
+	}
+}
+
+// emit 回调事件
+func (d *lockDebugger) emit(event *DebugEvent) {
+	if d.conf.Hook != nil {
+		d.conf.Hook(event)
+	}
+}
+
+// report 生成调试报告
+func (d *lockDebugger) report() *DebugReport {
+	now := time.Now()
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	res := &DebugReport{Time: now, Locks: make([]LockInfo, 0, len(d.locks))}
+	for _, r := range d.locks {
+		res.Locks = append(res.Locks, r.info(now))
+	}
+	sort.Slice(res.Locks, func(i, j int) bool { return res.Locks[i].Name < res.Locks[j].Name })
+	// 每个环只报告一次 (以环中最小协程 id 为起点)
+	for gid := range d.waiting {
+		cycle := d.findCycle(gid)
+		if cycle == nil {
+			continue
+		}
+		min := true
+		for _, e := range cycle {
+			if e.Goroutine < gid {
+				min = false
+			}
+		}
+		if min {
+			res.Deadlocks = append(res.Deadlocks, cycle)
+		}
+	}
+	return res
+}
+
+// info 锁调试信息 (需持有状态锁)
+func (r *lockRecord) info(now time.Time) LockInfo {
+	info := LockInfo{
+		Name:         r.name,
+		Owner:        r.owner,
+		AcquiredAt:   r.acquiredAt,
+		Stack:        r.stack,
+		Acquisitions: r.acquisitions,
+		Contentions:  r.contentions,
+		TotalWait:    r.totalWait,
+		MaxWait:      r.maxWait,
+		TotalHold:    r.totalHold,
+		MaxHold:      r.maxHold,
+	}
+	if r.owner != 0 {
+		info.HoldTime = now.Sub(r.acquiredAt)
+	}
+	for _, w := range r.waiters {
+		info.Waiters = append(info.Waiters, WaiterInfo{Goroutine: w.gid, Since: w.since, WaitTime: now.Sub(w.since), Stack: w.stack})
+	}
+	sort.Slice(info.Waiters, func(i, j int) bool { return info.Waiters[i].Since.Before(info.Waiters[j].Since) })
+	return info
+}
@@ -0,0 +1,133 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// fileLeaseState 文件锁状态 (JSON 存储于锁文件中)
+type fileLeaseState struct {
+	Owner    string `json:"owner"`     // 持有者标识
+	Token    uint64 `json:"token"`     // 最近一次发放的 fencing token
+	ExpireAt int64  `json:"expire_at"` // 租约过期时间 (unix 纳秒, 0 表示未持有)
+}
+
+// FileLocker 基于文件锁 (flock) 的多进程租约锁 (同一主机)
+// flock 仅在读写锁状态期间持有, 租约信息存储在锁文件中, 持有进程崩溃后租约到期自动失效
+type FileLocker struct {
+	conf *LockerConfig // 配置
+	dir  string        // 锁文件目录
+}
+
+// NewFileLocker 新建文件租约锁 (dir 不存在时自动创建)
+func NewFileLocker(dir string, conf *LockerConfig) (*FileLocker, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileLocker{conf: applyLockerConfig(conf), dir: dir}, nil
+}
+
+// Acquire 获取锁, 锁被持有时轮询等待
+func (l *FileLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	return pollAcquire(ctx, l.conf.RetryInterval, func() (*Lease, error) {
+		return l.TryAcquire(ctx, key, ttl)
+	})
+}
+
+// TryAcquire 尝试获取锁
+func (l *FileLocker) TryAcquire(_ context.Context, key string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+	var lease *Lease
+	err := l.update(key, func(s *fileLeaseState) (bool, error) {
+		now := time.Now()
+		if now.UnixNano() < s.ExpireAt {
+			return false, ErrLockHeld
+		}
+		s.Owner, s.ExpireAt = l.conf.Owner, now.Add(ttl).UnixNano()
+		s.Token++
+		lease = &Lease{Key: key, Owner: s.Owner, Token: s.Token, ExpireAt: time.Unix(0, s.ExpireAt)}
+		return true, nil
+	})
+	return lease, err
+}
+
+// Renew 续约
+func (l *FileLocker) Renew(_ context.Context, lease *Lease, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	return l.update(lease.Key, func(s *fileLeaseState) (bool, error) {
+		if !holdingFile(s, lease) {
+			return false, ErrLeaseLost
+		}
+		s.ExpireAt = time.Now().Add(ttl).UnixNano()
+		lease.ExpireAt = time.Unix(0, s.ExpireAt)
+		return true, nil
+	})
+}
+
+// Release 释放锁
+func (l *FileLocker) Release(_ context.Context, lease *Lease) error {
+	return l.update(lease.Key, func(s *fileLeaseState) (bool, error) {
+		if !holdingFile(s, lease) {
+			return false, ErrLeaseLost
+		}
+		s.ExpireAt = 0
+		return true, nil
+	})
+}
+
+// holdingFile 租约是否仍有效
+func holdingFile(s *fileLeaseState, lease *Lease) bool {
+	return s.Token == lease.Token && s.Owner == lease.Owner && time.Now().UnixNano() < s.ExpireAt
+}
+
+// path 锁文件路径 (key 转义为合法文件名)
+func (l *FileLocker) path(key string) string {
+	return filepath.Join(l.dir, url.PathEscape(key)+".lock")
+}
+
+// update 持有 flock 期间读取并更新锁状态
+// fn 返回 true 时写回状态
+func (l *FileLocker) update(key string, fn func(s *fileLeaseState) (bool, error)) error {
+	f, err := os.OpenFile(l.path(key), os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	if err = flock(f); err != nil {
+		return err
+	}
+	defer func() { _ = funlock(f) }()
+	state := &fileLeaseState{}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		if err = json.Unmarshal(data, state); err != nil {
+			return err
+		}
+	}
+	changed, err := fn(state)
+	if err != nil || !changed {
+		return err
+	}
+	if data, err = json.Marshal(state); err != nil {
+		return err
+	}
+	if err = f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err = f.WriteAt(data, 0); err != nil {
+		return err
+	}
+	return f.Sync()
+}
@@ -0,0 +1,15 @@
+//go:build !unix
+
+package lock
+
+import "os"
+
+// flock 当前平台不支持文件锁
+func flock(*os.File) error {
+	return ErrUnsupported
+}
+
+// funlock 当前平台不支持文件锁
+func funlock(*os.File) error {
+	return ErrUnsupported
+}
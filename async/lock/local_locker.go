@@ -0,0 +1,117 @@
+package lock
+
+import (
+	"context"
+	"time"
+)
+
+// localEntry 本地锁状态
+type localEntry struct {
+	owner    string        // 持有者标识
+	token    uint64        // 当前 fencing token
+	expireAt time.Time     // 租约过期时间 (零值表示未持有)
+	notify   chan struct{} // 释放通知 (关闭即广播)
+}
+
+// LocalLocker 进程内租约锁 (基于 CustomLock 保护锁状态)
+// 释放后锁状态即从状态表删除 (等待者被唤醒后重新创建), fencing token 由全局计数保证递增
+type LocalLocker struct {
+	conf    *LockerConfig          // 配置
+	lock    CustomLock             // 状态锁
+	entries map[string]*localEntry // 锁状态表 (仅保存持有中或过期未回收的锁)
+	token   uint64                 // fencing token 计数
+}
+
+// NewLocalLocker 新建进程内租约锁
+func NewLocalLocker(conf *LockerConfig) *LocalLocker {
+	return &LocalLocker{conf: applyLockerConfig(conf), entries: make(map[string]*localEntry)}
+}
+
+// Acquire 获取锁, 锁被持有时等待释放或租约过期
+func (l *LocalLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	for {
+		lease, wait, expireAt, err := l.tryAcquire(key, ttl)
+		if err != ErrLockHeld {
+			return lease, err
+		}
+		timer := time.NewTimer(time.Until(expireAt))
+		select {
+		case <-wait:
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		timer.Stop()
+	}
+}
+
+// TryAcquire 尝试获取锁
+func (l *LocalLocker) TryAcquire(_ context.Context, key string, ttl time.Duration) (*Lease, error) {
+	lease, _, _, err := l.tryAcquire(key, ttl)
+	return lease, err
+}
+
+// tryAcquire 尝试获取锁, 失败时返回释放通知及当前租约过期时间
+func (l *LocalLocker) tryAcquire(key string, ttl time.Duration) (*Lease, <-chan struct{}, time.Time, error) {
+	if ttl <= 0 {
+		return nil, nil, time.Time{}, ErrInvalidTTL
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	e, ok := l.entries[key]
+	if !ok {
+		e = &localEntry{}
+		l.entries[key] = e
+	}
+	now := time.Now()
+	if now.Before(e.expireAt) {
+		if e.notify == nil {
+			e.notify = make(chan struct{})
+		}
+		return nil, e.notify, e.expireAt, ErrLockHeld
+	}
+	l.token++
+	e.owner, e.token, e.expireAt = l.conf.Owner, l.token, now.Add(ttl)
+	return &Lease{Key: key, Owner: e.owner, Token: e.token, ExpireAt: e.expireAt}, nil, time.Time{}, nil
+}
+
+// Renew 续约
+func (l *LocalLocker) Renew(_ context.Context, lease *Lease, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	e, ok := l.holding(lease)
+	if !ok {
+		return ErrLeaseLost
+	}
+	e.expireAt = time.Now().Add(ttl)
+	lease.ExpireAt = e.expireAt
+	return nil
+}
+
+// Release 释放锁
+func (l *LocalLocker) Release(_ context.Context, lease *Lease) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	e, ok := l.holding(lease)
+	if !ok {
+		return ErrLeaseLost
+	}
+	if e.notify != nil {
+		close(e.notify)
+	}
+	delete(l.entries, lease.Key)
+	return nil
+}
+
+// holding 租约是否仍有效 (需持有状态锁)
+func (l *LocalLocker) holding(lease *Lease) (*localEntry, bool) {
+	e, ok := l.entries[lease.Key]
+	if !ok || e.token != lease.Token || !time.Now().Before(e.expireAt) {
+		return nil, false
+	}
+	return e, true
+}
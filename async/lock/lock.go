@@ -1,9 +1,12 @@
 package lock
 
 import (
+	"context"
 	"runtime"
+	"sync"
 	"sync/atomic"
 	"time"
+	"unsafe"
 
 	"github.com/Anonymouscn/go-partner/async"
 )
@@ -14,13 +17,68 @@ const (
 	maxSpins   = 4         // 最大自旋次数
 	yieldSpins = 2         // 进入 yield 阶段临界值
 	maxBackoff = 32        // 最大退避时间
+
+	cacheLineSize = 64 // 缓存行大小
+
+	starvationThreshold = time.Millisecond // 饥饿判定阈值 (等待者等待超过该时间时进入饥饿模式)
 )
 
 // CustomLock 自定义锁 (试验中)
+// 普通模式: 新来者可与被唤醒的等待者竞争 (短暂自旋后挂起排队), 吞吐量高;
+// 公平模式 (SetFair): 严格按到达顺序 (FIFO) 获取, 解锁时直接移交给队首等待者;
+// 饥饿模式: 普通模式下等待者等待超过 1ms 时自动进入, 行为与公平模式一致, 队列清空或队首等待时间不足 1ms 时退出
 type CustomLock struct {
-	state  uint64   // 高32位存储 owner，低32位存储 state
-	waiter uint32   // 等待者计数
-	_      [48]byte // 字节填充缓存行, 避免伪共享
+	lockState
+	_ [(cacheLineSize - unsafe.Sizeof(lockState{})%cacheLineSize) % cacheLineSize]byte // 字节填充至缓存行整数倍, 避免伪共享
+}
+
+// lockState CustomLock 状态 (单独定义以计算缓存行填充)
+type lockState struct {
+	state    uint64        // 高32位存储 owner，低32位存储 state
+	waiter   uint32        // 等待者计数
+	fair     uint32        // 公平模式 (1 开启)
+	starving uint32        // 饥饿模式 (1 开启)
+	qlock    sync.Mutex    // 等待队列锁
+	queue    []*lockWaiter // 挂起等待者队列
+}
+
+// lockWaiter 挂起等待者
+type lockWaiter struct {
+	gid     uint64        // 等待者 go routine id (已偏移)
+	since   time.Time     // 开始等待时间
+	ready   chan struct{} // 唤醒信号
+	handoff bool          // 是否已直接移交锁
+}
+
+// NewFairLock 新建公平锁 (FIFO)
+func NewFairLock() *CustomLock {
+	l := &CustomLock{}
+	l.SetFair(true)
+	return l
+}
+
+// SetFair 设置公平模式
+func (l *CustomLock) SetFair(fair bool) {
+	if fair {
+		atomic.StoreUint32(&l.fair, 1)
+	} else {
+		atomic.StoreUint32(&l.fair, 0)
+	}
+}
+
+// IsFair 是否为公平模式
+func (l *CustomLock) IsFair() bool {
+	return atomic.LoadUint32(&l.fair) == 1
+}
+
+// IsStarving 是否处于饥饿模式
+func (l *CustomLock) IsStarving() bool {
+	return atomic.LoadUint32(&l.starving) == 1
+}
+
+// handoffMode 是否为移交模式 (公平模式或饥饿模式)
+func (l *CustomLock) handoffMode() bool {
+	return l.IsFair() || l.IsStarving()
 }
 
 // 快速获取锁（无竞争路径）
@@ -37,111 +95,159 @@ func (l *CustomLock) isReentrant(gid uint64) bool {
 	return atomic.LoadUint64(&l.state)>>ownerShift == gid>>ownerShift
 }
 
+// tryFast 快速路径 (移交模式下存在等待者时不插队)
+func (l *CustomLock) tryFast(gid uint64) bool {
+	if (!l.handoffMode() || atomic.LoadUint32(&l.waiter) == 0) && l.fastLock(gid) {
+		return true
+	}
+	return l.isReentrant(gid)
+}
+
 // Lock 上锁
 func (l *CustomLock) Lock() {
 	// 获取当前goroutine ID（只获取一次）
 	gid := uint64(async.GetGoRoutineID()) << ownerShift
-	// 快速路径1：无竞争直接获取
-	if l.fastLock(gid) {
+	if debugEnabled() {
+		_ = l.lockDebug(context.Background(), gid)
 		return
 	}
-	// 快速路径2：检查重入
-	if l.isReentrant(gid) {
+	if l.tryFast(gid) {
 		return
 	}
-	// 增加等待者计数
-	atomic.AddUint32(&l.waiter, 1)
-	defer atomic.AddUint32(&l.waiter, ^uint32(0))
-	// 自旋获取锁
-	spins := 0
-	backoff := uint32(1)
-	var lastState uint64
-	for {
-		state := atomic.LoadUint64(&l.state)
-		// 避免在状态未改变时进行 CAS
-		if state == lastState {
-			// 优化的自旋策略
-			if spins < maxSpins {
-				spins++
-				if spins < yieldSpins {
-					// 短自旋
-					for i := 0; i < int(backoff); i++ {
-						runtime.Gosched()
-					}
-					// 指数退避，但有上限
-					if backoff < maxBackoff {
-						backoff <<= 1
-					}
-				} else {
-					runtime.Gosched()
-				}
-				continue
-			}
-			// 超过自旋限制，进入睡眠
-			runtime.Gosched()
-			spins = 0
-			backoff = 1
-			continue
-		}
-		// 状态发生改变，尝试获取锁
-		if state == 0 {
-			if atomic.CompareAndSwapUint64(&l.state, 0, locked|gid) {
-				return
-			}
-		}
-		// 更新上次观察到的状态
-		lastState = state
+	_ = l.lockSlow(context.Background(), gid)
+}
+
+// LockContext 上锁 (上下文取消时返回 ctx.Err())
+func (l *CustomLock) LockContext(ctx context.Context) error {
+	gid := uint64(async.GetGoRoutineID()) << ownerShift
+	if debugEnabled() {
+		return l.lockDebug(ctx, gid)
+	}
+	if l.tryFast(gid) {
+		return nil
 	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return l.lockSlow(ctx, gid)
 }
 
 // TryLock 尝试上锁
 func (l *CustomLock) TryLock(ttl time.Duration) bool {
 	gid := uint64(async.GetGoRoutineID()) << ownerShift
-	deadline := time.Now().Add(ttl)
-	// 快速路径
-	if l.fastLock(gid) {
-		return true
+	if debugEnabled() {
+		ctx, cancel := context.WithTimeout(context.Background(), ttl)
+		defer cancel()
+		return l.lockDebug(ctx, gid) == nil
 	}
-	// 检查重入
-	if l.isReentrant(gid) {
+	if l.tryFast(gid) {
 		return true
 	}
+	if ttl <= 0 {
+		return false
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), ttl)
+	defer cancel()
+	return l.lockSlow(ctx, gid) == nil
+}
+
+// lockSlow 竞争路径: 普通模式下先短暂自旋, 仍未获取时挂起排队
+func (l *CustomLock) lockSlow(ctx context.Context, gid uint64) error {
 	// 增加等待者计数
 	atomic.AddUint32(&l.waiter, 1)
 	defer atomic.AddUint32(&l.waiter, ^uint32(0))
-	spins := 0
+	if !l.handoffMode() && l.spin(gid) {
+		return nil
+	}
+	since := time.Now()
+	front := false
+	for {
+		l.qlock.Lock()
+		// 持有队列锁时重新检查, 避免错过唤醒 (移交模式下仅队列为空或被唤醒的等待者可直接获取)
+		if (!l.handoffMode() || len(l.queue) == 0 || front) && l.fastLock(gid) {
+			l.qlock.Unlock()
+			return nil
+		}
+		w := &lockWaiter{gid: gid, since: since, ready: make(chan struct{})}
+		// 被唤醒后竞争失败的等待者排在队首
+		if front {
+			l.queue = append([]*lockWaiter{w}, l.queue...)
+		} else {
+			l.queue = append(l.queue, w)
+		}
+		l.qlock.Unlock()
+		select {
+		case <-w.ready:
+		case <-ctx.Done():
+			return l.abandon(w, ctx.Err())
+		}
+		if w.handoff {
+			return nil
+		}
+		// 普通模式被唤醒: 等待过久时进入饥饿模式
+		if time.Since(since) > starvationThreshold {
+			atomic.StoreUint32(&l.starving, 1)
+		}
+		front = true
+	}
+}
+
+// spin 短暂自旋获取锁
+func (l *CustomLock) spin(gid uint64) bool {
 	backoff := uint32(1)
-	var lastState uint64
-	for time.Now().Before(deadline) {
-		state := atomic.LoadUint64(&l.state)
-		if state == lastState {
-			if spins < maxSpins {
-				spins++
-				if spins < yieldSpins {
-					for i := 0; i < int(backoff); i++ {
-						runtime.Gosched()
-					}
-					if backoff < maxBackoff {
-						backoff <<= 1
-					}
-				} else {
-					runtime.Gosched()
-				}
-				continue
+	for spins := 0; spins < maxSpins; spins++ {
+		if atomic.LoadUint64(&l.state) == 0 && l.fastLock(gid) {
+			return true
+		}
+		if spins < yieldSpins {
+			// 短自旋
+			for i := 0; i < int(backoff); i++ {
+				runtime.Gosched()
+			}
+			// 指数退避，但有上限
+			if backoff < maxBackoff {
+				backoff <<= 1
 			}
-			runtime.Gosched()
-			spins = 0
-			backoff = 1
 			continue
 		}
-		if state == 0 && atomic.CompareAndSwapUint64(&l.state, 0, locked|gid) {
-			return true
-		}
-		lastState = state
+		runtime.Gosched()
 	}
 	return false
 }
 
+// abandon 放弃等待 (上下文取消)
+func (l *CustomLock) abandon(w *lockWaiter, err error) error {
+	l.qlock.Lock()
+	for i, e := range l.queue {
+		if e == w {
+			l.queue = append(l.queue[:i], l.queue[i+1:]...)
+			l.qlock.Unlock()
+			return err
+		}
+	}
+	l.qlock.Unlock()
+	// 取消与唤醒同时发生: 已移交的锁需释放, 普通唤醒需传递给下一个等待者
+	<-w.ready
+	if w.handoff {
+		l.release(w.gid)
+		return err
+	}
+	l.qlock.Lock()
+	l.wakeNext()
+	l.qlock.Unlock()
+	return err
+}
+
+// wakeNext 锁空闲时唤醒队首等待者 (需持有队列锁)
+func (l *CustomLock) wakeNext() {
+	if len(l.queue) == 0 || atomic.LoadUint64(&l.state) != 0 {
+		return
+	}
+	w := l.queue[0]
+	l.queue = l.queue[1:]
+	close(w.ready)
+}
+
 // Unlock 解锁
 func (l *CustomLock) Unlock() {
 	gid := uint64(async.GetGoRoutineID()) << ownerShift
@@ -150,19 +256,52 @@ func (l *CustomLock) Unlock() {
 	if state>>ownerShift != gid>>ownerShift {
 		panic("unlock of mutex not owned by current goroutine")
 	}
+	if debugEnabled() {
+		if d := currentDebugger(); d != nil {
+			d.released(l)
+		}
+	}
 	// 检查是否有等待者
-	if atomic.LoadUint32(&l.waiter) == 0 {
-		// 无等待者，直接释放
+	if atomic.LoadUint32(&l.waiter) == 0 && atomic.CompareAndSwapUint64(&l.state, state, 0) {
+		// 无等待者，直接释放; 释放期间新到达的等待者可能已挂起, 需唤醒
+		if atomic.LoadUint32(&l.waiter) != 0 {
+			l.qlock.Lock()
+			l.wakeNext()
+			l.qlock.Unlock()
+		}
+		return
+	}
+	l.release(gid)
+}
+
+// release 释放锁并处理等待队列
+func (l *CustomLock) release(gid uint64) {
+	l.qlock.Lock()
+	defer l.qlock.Unlock()
+	if state := atomic.LoadUint64(&l.state); state>>ownerShift != gid>>ownerShift {
+		panic("unlock of mutex not owned by current goroutine")
+	}
+	if len(l.queue) == 0 {
 		atomic.StoreUint64(&l.state, 0)
+		atomic.StoreUint32(&l.starving, 0)
 		return
 	}
-	// 有等待者，使用 自旋 + CAS 确保正确释放
-	for !atomic.CompareAndSwapUint64(&l.state, state, 0) {
-		state = atomic.LoadUint64(&l.state)
-		if state>>ownerShift != gid>>ownerShift {
-			panic("unlock of mutex not owned by current goroutine")
-		}
+	if !l.handoffMode() {
+		// 普通模式: 释放后唤醒队首等待者与新来者竞争
+		atomic.StoreUint64(&l.state, 0)
+		l.wakeNext()
+		return
+	}
+	// 移交模式: 直接移交给队首等待者
+	w := l.queue[0]
+	l.queue = l.queue[1:]
+	atomic.StoreUint64(&l.state, locked|w.gid)
+	w.handoff = true
+	// 队列清空或队首等待时间不足阈值时退出饥饿模式
+	if len(l.queue) == 0 || time.Since(w.since) < starvationThreshold {
+		atomic.StoreUint32(&l.starving, 0)
 	}
+	close(w.ready)
 }
 
 // IsLocked 是否上锁
@@ -0,0 +1,86 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/base"
+)
+
+var (
+	ErrLockHeld    = errors.New("lock: lock is held by another owner")          // 锁被其他持有者持有
+	ErrLeaseLost   = errors.New("lock: lease expired or not owned")             // 租约已过期或已被其他持有者获取
+	ErrInvalidTTL  = errors.New("lock: lease ttl must be positive")             // 租约时间非法
+	ErrUnsupported = errors.New("lock: backend not supported on this platform") // 当前平台不支持该后端
+)
+
+// Lease 锁租约
+type Lease struct {
+	Key      string    // 锁 key
+	Owner    string    // 持有者标识
+	Token    uint64    // fencing token (同一 key 每次获取单调递增, 下游可据此拒绝过期持有者的写入)
+	ExpireAt time.Time // 租约过期时间
+}
+
+// Expired 租约是否已过期 (按本地时间判断)
+func (l *Lease) Expired() bool {
+	return !time.Now().Before(l.ExpireAt)
+}
+
+// Locker 租约锁 (单进程/多进程/分布式后端统一接口)
+type Locker interface {
+	// Acquire 获取锁, 锁被持有时等待直到获取成功或上下文取消
+	Acquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error)
+	// TryAcquire 尝试获取锁, 锁被持有时返回 ErrLockHeld
+	TryAcquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error)
+	// Renew 续约 (租约已过期或已被其他持有者获取时返回 ErrLeaseLost)
+	Renew(ctx context.Context, lease *Lease, ttl time.Duration) error
+	// Release 释放锁 (token 不匹配时返回 ErrLeaseLost)
+	Release(ctx context.Context, lease *Lease) error
+}
+
+// LockerConfig Locker 配置
+type LockerConfig struct {
+	Owner         string        // 持有者标识 (默认 hostname-pid-随机串)
+	RetryInterval time.Duration // Acquire 轮询间隔 (默认 20ms, 本地后端不轮询)
+}
+
+// applyLockerConfig 填充默认配置
+func applyLockerConfig(conf *LockerConfig) *LockerConfig {
+	if conf == nil {
+		conf = &LockerConfig{}
+	}
+	conf.Owner = base.SetOrDefault(conf.Owner, defaultOwner())
+	conf.RetryInterval = base.SetOrDefault(conf.RetryInterval, 20*time.Millisecond)
+	return conf
+}
+
+// defaultOwner 默认持有者标识
+func defaultOwner() string {
+	host, _ := os.Hostname()
+	buf := make([]byte, 4)
+	_, _ = rand.Read(buf)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(buf))
+}
+
+// pollAcquire 轮询获取锁直到成功或上下文取消
+func pollAcquire(ctx context.Context, interval time.Duration, try func() (*Lease, error)) (*Lease, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		lease, err := try()
+		if !errors.Is(err, ErrLockHeld) {
+			return lease, err
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package lock
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	ErrSemaphoreWeight = errors.New("lock: acquire weight exceeds semaphore size") // 申请权重超过信号量容量
+	ErrBarrierBroken   = errors.New("lock: barrier is broken")                     // 屏障已损坏
+)
+
+// ================================ 加权信号量 ================================ //
+
+// semWaiter 信号量等待者
+type semWaiter struct {
+	n     int64         // 申请权重
+	ready chan struct{} // 获取成功信号
+}
+
+// Semaphore 加权信号量 (等待者按 FIFO 顺序获取, 避免大权重申请饥饿)
+type Semaphore struct {
+	size    int64      // 容量
+	cur     int64      // 已占用权重
+	lock    sync.Mutex // 状态锁
+	waiters list.List  // 等待队列
+}
+
+// NewSemaphore 新建加权信号量
+func NewSemaphore(size int64) *Semaphore {
+	return &Semaphore{size: size}
+}
+
+// Acquire 获取 n 个权重 (不足时挂起等待, 上下文取消时返回 ctx.Err())
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	s.lock.Lock()
+	if n > s.size {
+		s.lock.Unlock()
+		return ErrSemaphoreWeight
+	}
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.lock.Unlock()
+		return nil
+	}
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.lock.Unlock()
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		select {
+		case <-w.ready:
+			// 取消与获取同时发生, 视为获取成功后立即归还
+			s.cur -= n
+			s.notifyWaiters()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			// 队首等待者放弃后, 后续等待者可能已满足条件
+			if isFront && s.size > s.cur {
+				s.notifyWaiters()
+			}
+		}
+		return ctx.Err()
+	}
+}
+
+// TryAcquire 尝试获取 n 个权重 (不足或有等待者时返回 false)
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		return true
+	}
+	return false
+}
+
+// Release 归还 n 个权重 (归还超过已占用权重时 panic)
+func (s *Semaphore) Release(n int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cur -= n
+	if s.cur < 0 {
+		panic("lock: semaphore released more than held")
+	}
+	s.notifyWaiters()
+}
+
+// Available 可用权重
+func (s *Semaphore) Available() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.size - s.cur
+}
+
+// notifyWaiters 按顺序唤醒可满足的等待者 (需持有锁)
+func (s *Semaphore) notifyWaiters() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*semWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+// ================================ 倒计时门闩 ================================ //
+
+// Latch 倒计时门闩 (计数归零后所有等待者放行, 不可重置)
+type Latch struct {
+	count int64         // 剩余计数
+	lock  sync.Mutex    // 状态锁
+	done  chan struct{} // 归零信号
+}
+
+// NewLatch 新建倒计时门闩
+func NewLatch(count int64) *Latch {
+	l := &Latch{count: count, done: make(chan struct{})}
+	if count <= 0 {
+		close(l.done)
+	}
+	return l
+}
+
+// CountDown 计数减一 (归零后调用无效果)
+func (l *Latch) CountDown() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.count <= 0 {
+		return
+	}
+	l.count--
+	if l.count == 0 {
+		close(l.done)
+	}
+}
+
+// Count 剩余计数
+func (l *Latch) Count() int64 {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.count
+}
+
+// Done 归零信号
+func (l *Latch) Done() <-chan struct{} {
+	return l.done
+}
+
+// Wait 等待计数归零 (上下文取消时返回 ctx.Err())
+func (l *Latch) Wait(ctx context.Context) error {
+	select {
+	case <-l.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ================================ 循环屏障 ================================ //
+
+// barrierGen 屏障代
+type barrierGen struct {
+	done   chan struct{} // 放行/损坏信号
+	broken bool          // 是否已损坏
+}
+
+// Barrier 可重用循环屏障 (parties 个协程全部到达后同时放行, 随后自动进入下一代)
+// 任一等待者上下文取消时当代屏障损坏, 其余等待者返回 ErrBarrierBroken, 需调用 Reset 重置
+// 放行动作在锁外执行, 动作 panic 时屏障损坏, panic 传递给最后到达者
+type Barrier struct {
+	parties int         // 参与者数
+	action  func()      // 全部到达后由最后到达者执行的动作
+	lock    sync.Mutex  // 状态锁
+	count   int         // 当代已到达数
+	gen     *barrierGen // 当代
+}
+
+// NewBarrier 新建循环屏障 (action 可为 nil)
+func NewBarrier(parties int, action func()) *Barrier {
+	return &Barrier{parties: parties, action: action, gen: &barrierGen{done: make(chan struct{})}}
+}
+
+// Await 到达屏障并等待其他参与者
+// 返回到达序号 (parties-1 为第一个到达, 0 为最后到达); 屏障损坏时返回 ErrBarrierBroken
+func (b *Barrier) Await(ctx context.Context) (int, error) {
+	b.lock.Lock()
+	gen := b.gen
+	if gen.broken {
+		b.lock.Unlock()
+		return -1, ErrBarrierBroken
+	}
+	b.count++
+	index := b.parties - b.count
+	if index == 0 {
+		b.next()
+		b.lock.Unlock()
+		b.trip(gen)
+		return 0, nil
+	}
+	b.lock.Unlock()
+	select {
+	case <-gen.done:
+		if gen.broken {
+			return index, ErrBarrierBroken
+		}
+		return index, nil
+	case <-ctx.Done():
+		b.lock.Lock()
+		if b.gen == gen && !gen.broken {
+			b.breakBarrier()
+			b.lock.Unlock()
+			return index, ctx.Err()
+		}
+		b.lock.Unlock()
+		// 取消与放行同时发生时以放行为准 (等待放行动作执行结束)
+		<-gen.done
+		if gen.broken {
+			return index, ErrBarrierBroken
+		}
+		return index, nil
+	}
+}
+
+// Reset 重置屏障 (当代等待者返回 ErrBarrierBroken)
+func (b *Barrier) Reset() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if !b.gen.broken {
+		b.breakBarrier()
+	}
+	b.gen = &barrierGen{done: make(chan struct{})}
+	b.count = 0
+}
+
+// Waiting 当代等待中的参与者数
+func (b *Barrier) Waiting() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.count
+}
+
+// IsBroken 当代屏障是否已损坏
+func (b *Barrier) IsBroken() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.gen.broken
+}
+
+// next 进入下一代 (需持有锁; 当代由 trip 放行)
+func (b *Barrier) next() {
+	b.gen = &barrierGen{done: make(chan struct{})}
+	b.count = 0
+}
+
+// trip 执行放行动作并放行指定代 (动作 panic 时损坏该代及当前代后继续 panic)
+func (b *Barrier) trip(gen *barrierGen) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.lock.Lock()
+			gen.broken = true
+			close(gen.done)
+			if !b.gen.broken {
+				b.breakBarrier()
+			}
+			b.lock.Unlock()
+			panic(r)
+		}
+	}()
+	if b.action != nil {
+		b.action()
+	}
+	close(gen.done)
+}
+
+// breakBarrier 损坏当代屏障 (需持有锁)
+func (b *Barrier) breakBarrier() {
+	b.gen.broken = true
+	close(b.gen.done)
+}
+
+// ================================ 广播事件 ================================ //
+
+// Event 可广播事件 (Set 后所有等待者放行, Clear 后重新阻塞; 零值可用)
+type Event struct {
+	lock sync.Mutex    // 状态锁
+	set  bool          // 是否已触发
+	ch   chan struct{} // 触发信号
+}
+
+// Set 触发事件, 唤醒全部等待者
+func (e *Event) Set() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.set {
+		return
+	}
+	e.set = true
+	if e.ch != nil {
+		close(e.ch)
+	} else {
+		e.ch = closedChan
+	}
+}
+
+// Clear 重置事件
+func (e *Event) Clear() {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.set {
+		e.set, e.ch = false, nil
+	}
+}
+
+// IsSet 是否已触发
+func (e *Event) IsSet() bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.set
+}
+
+// Done 当前触发信号 (Clear 后需重新获取)
+func (e *Event) Done() <-chan struct{} {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.ch == nil {
+		e.ch = make(chan struct{})
+	}
+	return e.ch
+}
+
+// Wait 等待事件触发 (上下文取消时返回 ctx.Err())
+func (e *Event) Wait(ctx context.Context) error {
+	select {
+	case <-e.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// closedChan 已关闭的信号
+var closedChan = func() chan struct{} {
+	ch := make(chan struct{})
+	close(ch)
+	return ch
+}()
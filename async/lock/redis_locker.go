@@ -0,0 +1,125 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedisClient Redis 客户端 (由使用方基于具体客户端适配, 如 go-redis 的 Eval)
+// 返回值遵循 Redis 协议: 整数回复为 int64
+type RedisClient interface {
+	Eval(ctx context.Context, script string, keys []string, args ...any) (any, error)
+}
+
+// Redis 锁脚本
+// KEYS[1]: 锁 key, KEYS[2]: fencing token 计数 key; ARGV[1]: 持有者标识, ARGV[2]: 租约毫秒数 / 锁值
+const (
+	// RedisAcquireScript 获取锁 (返回 token, 锁被持有时返回 0)
+	RedisAcquireScript = `if redis.call('EXISTS', KEYS[1]) == 1 then return 0 end
+local token = redis.call('INCR', KEYS[2])
+redis.call('SET', KEYS[1], ARGV[1] .. ':' .. token, 'PX', ARGV[2])
+return token`
+	// RedisRenewScript 续约 (锁值匹配时返回 1, 否则返回 0)
+	RedisRenewScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then return redis.call('PEXPIRE', KEYS[1], ARGV[2]) end
+return 0`
+	// RedisReleaseScript 释放锁 (锁值匹配时返回 1, 否则返回 0)
+	RedisReleaseScript = `if redis.call('GET', KEYS[1]) == ARGV[1] then return redis.call('DEL', KEYS[1]) end
+return 0`
+)
+
+// RedisLocker 基于 Redis 的分布式租约锁
+type RedisLocker struct {
+	conf   *LockerConfig // 配置
+	client RedisClient   // Redis 客户端
+	prefix string        // key 前缀
+}
+
+// NewRedisLocker 新建 Redis 租约锁 (prefix 为空时使用 "lock:")
+func NewRedisLocker(client RedisClient, prefix string, conf *LockerConfig) *RedisLocker {
+	if prefix == "" {
+		prefix = "lock:"
+	}
+	return &RedisLocker{conf: applyLockerConfig(conf), client: client, prefix: prefix}
+}
+
+// Acquire 获取锁, 锁被持有时轮询等待
+func (l *RedisLocker) Acquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	return pollAcquire(ctx, l.conf.RetryInterval, func() (*Lease, error) {
+		return l.TryAcquire(ctx, key, ttl)
+	})
+}
+
+// TryAcquire 尝试获取锁
+func (l *RedisLocker) TryAcquire(ctx context.Context, key string, ttl time.Duration) (*Lease, error) {
+	if ttl <= 0 {
+		return nil, ErrInvalidTTL
+	}
+	expireAt := time.Now().Add(ttl)
+	res, err := l.client.Eval(ctx, RedisAcquireScript, l.keys(key), l.conf.Owner, ttl.Milliseconds())
+	if err != nil {
+		return nil, err
+	}
+	token, err := redisInt(res)
+	if err != nil {
+		return nil, err
+	}
+	if token == 0 {
+		return nil, ErrLockHeld
+	}
+	return &Lease{Key: key, Owner: l.conf.Owner, Token: uint64(token), ExpireAt: expireAt}, nil
+}
+
+// Renew 续约
+func (l *RedisLocker) Renew(ctx context.Context, lease *Lease, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	expireAt := time.Now().Add(ttl)
+	if err := l.eval(ctx, RedisRenewScript, lease, ttl.Milliseconds()); err != nil {
+		return err
+	}
+	lease.ExpireAt = expireAt
+	return nil
+}
+
+// Release 释放锁
+func (l *RedisLocker) Release(ctx context.Context, lease *Lease) error {
+	return l.eval(ctx, RedisReleaseScript, lease)
+}
+
+// eval 执行锁值校验脚本 (返回 0 时为 ErrLeaseLost)
+func (l *RedisLocker) eval(ctx context.Context, script string, lease *Lease, args ...any) error {
+	value := fmt.Sprintf("%s:%d", lease.Owner, lease.Token)
+	res, err := l.client.Eval(ctx, script, l.keys(lease.Key), append([]any{value}, args...)...)
+	if err != nil {
+		return err
+	}
+	n, err := redisInt(res)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// keys 锁 key 及 token 计数 key (使用 hash tag 保证 Redis Cluster 下两个 key 位于同一 slot)
+func (l *RedisLocker) keys(key string) []string {
+	tagged := l.prefix + "{" + key + "}"
+	return []string{tagged, tagged + ":token"}
+}
+
+// redisInt 解析整数回复
+func redisInt(res any) (int64, error) {
+	switch v := res.(type) {
+	case int64:
+		return v, nil
+	case int:
+		return int64(v), nil
+	case nil:
+		return 0, nil
+	}
+	return 0, fmt.Errorf("lock: unexpected redis reply %T", res)
+}
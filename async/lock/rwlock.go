@@ -0,0 +1,181 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// ErrLockUpgrade 持有读锁时申请写锁 (读锁升级会导致死锁, 需先释放读锁)
+var ErrLockUpgrade = errors.New("lock: cannot upgrade read lock to write lock")
+
+// ReentrantRWLock 协程所有的可重入读写锁
+// 1. 读锁/写锁均可重入, 按协程记录持有次数, 释放次数与获取次数一致时才真正释放;
+// 2. 写锁优先: 存在等待中的写者时, 新读者阻塞 (已持有读锁的协程重入不受影响), 避免写者饥饿;
+// 3. 持有写锁的协程可再获取读锁 (降级), 持有读锁的协程获取写锁返回 ErrLockUpgrade 而不是死锁
+type ReentrantRWLock struct {
+	lock       sync.Mutex    // 状态锁
+	writer     int32         // 写锁持有者 go routine id
+	writeHolds int           // 写锁持有次数 (0 表示未持有写锁)
+	readers    map[int32]int // 读锁持有者 go routine id -> 持有次数
+	waiting    int           // 等待中的写者数
+	notify     chan struct{} // 状态变更通知 (关闭即广播)
+}
+
+// Lock 获取写锁 (持有读锁时 panic ErrLockUpgrade)
+func (l *ReentrantRWLock) Lock() {
+	if err := l.TryLock(context.Background()); err != nil {
+		panic(err)
+	}
+}
+
+// TryLock 获取写锁, 上下文取消时返回 ctx.Err(), 持有读锁时返回 ErrLockUpgrade
+func (l *ReentrantRWLock) TryLock(ctx context.Context) error {
+	gid := async.GetGoRoutineID()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	// 写锁重入
+	if l.writeHolds > 0 && l.writer == gid {
+		l.writeHolds++
+		return nil
+	}
+	if l.readers[gid] > 0 {
+		return ErrLockUpgrade
+	}
+	l.waiting++
+	defer func() { l.waiting-- }()
+	for l.writeHolds > 0 || len(l.readers) > 0 {
+		if err := l.wait(ctx); err != nil {
+			// 放弃等待后唤醒因写锁优先而阻塞的读者
+			l.broadcast()
+			return err
+		}
+	}
+	l.writer, l.writeHolds = gid, 1
+	return nil
+}
+
+// Unlock 释放写锁 (非持有者释放时 panic)
+func (l *ReentrantRWLock) Unlock() {
+	gid := async.GetGoRoutineID()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.writeHolds == 0 || l.writer != gid {
+		panic("unlock of rwlock not write-locked by current goroutine")
+	}
+	l.writeHolds--
+	if l.writeHolds == 0 {
+		l.writer = 0
+		l.broadcast()
+	}
+}
+
+// RLock 获取读锁
+func (l *ReentrantRWLock) RLock() {
+	_ = l.TryRLock(context.Background())
+}
+
+// TryRLock 获取读锁, 上下文取消时返回 ctx.Err()
+func (l *ReentrantRWLock) TryRLock(ctx context.Context) error {
+	gid := async.GetGoRoutineID()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	// 读锁重入或持有写锁时直接获取
+	if l.readers[gid] > 0 || (l.writeHolds > 0 && l.writer == gid) {
+		l.acquireRead(gid)
+		return nil
+	}
+	for l.writeHolds > 0 || l.waiting > 0 {
+		if err := l.wait(ctx); err != nil {
+			return err
+		}
+	}
+	l.acquireRead(gid)
+	return nil
+}
+
+// RUnlock 释放读锁 (非持有者释放时 panic)
+func (l *ReentrantRWLock) RUnlock() {
+	gid := async.GetGoRoutineID()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	n := l.readers[gid]
+	if n == 0 {
+		panic("runlock of rwlock not read-locked by current goroutine")
+	}
+	if n > 1 {
+		l.readers[gid] = n - 1
+		return
+	}
+	delete(l.readers, gid)
+	if len(l.readers) == 0 {
+		l.broadcast()
+	}
+}
+
+// HoldCount 当前协程的写锁持有次数
+func (l *ReentrantRWLock) HoldCount() int {
+	gid := async.GetGoRoutineID()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.writer != gid {
+		return 0
+	}
+	return l.writeHolds
+}
+
+// ReadHoldCount 当前协程的读锁持有次数
+func (l *ReentrantRWLock) ReadHoldCount() int {
+	gid := async.GetGoRoutineID()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.readers[gid]
+}
+
+// IsLocked 是否持有写锁 (任意协程)
+func (l *ReentrantRWLock) IsLocked() bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.writeHolds > 0
+}
+
+// Readers 持有读锁的协程数
+func (l *ReentrantRWLock) Readers() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return len(l.readers)
+}
+
+// acquireRead 登记读锁 (需持有状态锁)
+func (l *ReentrantRWLock) acquireRead(gid int32) {
+	if l.readers == nil {
+		l.readers = make(map[int32]int)
+	}
+	l.readers[gid]++
+}
+
+// wait 释放状态锁等待状态变更 (需持有状态锁, 返回时重新持有)
+func (l *ReentrantRWLock) wait(ctx context.Context) error {
+	if l.notify == nil {
+		l.notify = make(chan struct{})
+	}
+	ch := l.notify
+	l.lock.Unlock()
+	defer l.lock.Lock()
+	select {
+	case <-ch:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// broadcast 通知全部等待者 (需持有状态锁)
+func (l *ReentrantRWLock) broadcast() {
+	if l.notify != nil {
+		close(l.notify)
+		l.notify = nil
+	}
+}
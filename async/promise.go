@@ -2,14 +2,26 @@ package async
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"runtime/debug"
+	"sync"
 )
 
 // Action 异步操作
 // res: 上一步执行结果, fn: 本次执行函数, args: 本次执行函数入参
 //type Action func(fn any, args ...any)
 
+// ErrInvalidAction 非法 Action (Fn 不是函数或参数不合法)
+var ErrInvalidAction = errors.New("async: invalid action")
+
+// errorType error 接口类型
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
 // Action 执行操作
+// 执行时入参为: 上一步执行结果 (按函数剩余入参个数截取) + Args;
+// 若函数最后一个返回值为 error 且非空, 视为执行失败, error 不会传递给下一步
 type Action struct {
 	Fn   any   // 本次执行函数
 	Args []any // 本次执行函数入参
@@ -30,47 +42,115 @@ func (action *Action) Valid() (bool, reflect.Value) {
 	return true, fn
 }
 
+// call 执行操作 (panic 转换为 PanicError)
+// prev: 上一步执行结果
+func (action *Action) call(prev []any) (res []any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			res, err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	ok, fn := action.Valid()
+	if !ok {
+		return nil, ErrInvalidAction
+	}
+	in, err := buildParams(fn.Type(), prev, action.Args)
+	if err != nil {
+		return nil, err
+	}
+	return convertResult(fn.Type(), fn.Call(in))
+}
+
+// stage 执行阶段类型
+const (
+	thenStage    = iota // 执行阶段 (Then)
+	catchStage          // 错误处理阶段 (Catch)
+	finallyStage        // 最终处理阶段 (Finally)
+)
+
+// stage 执行阶段
+type stage struct {
+	kind    int               // 阶段类型
+	actions []*Action         // 执行操作 (多个操作并行执行)
+	catch   func(error) []any // 错误处理方法
+	finally func()            // 最终处理方法
+}
+
 // Promise 异步结果 (promise 语法糖)
 type Promise struct {
-	actions [][]*Action     // 操作表
+	stages  []*stage        // 执行阶段表
 	ctx     context.Context // 上下文
 	cursor  int64           // 调用栈指针
 	errors  []*error        // 错误栈
 	config  *PromiseConfig  // 配置
+	results []any           // 最近一次执行结果
+	err     error           // 未处理错误
+	running bool            // 是否执行中
+	lock    sync.Mutex      // 状态锁
+	cond    *sync.Cond      // 执行完成通知
 }
 
 // PromiseConfig Promise 配置
 type PromiseConfig struct {
+	Context context.Context // 上下文 (取消后跳过剩余 Then 阶段)
 }
 
 // NewPromise 新建 Promise 异步逻辑
 func NewPromise() *Promise {
-	return &Promise{
-		actions: make([][]*Action, 0),
-		ctx:     context.Background(),
-		cursor:  0,
-		errors:  make([]*error, 0),
+	p := &Promise{
+		stages: make([]*stage, 0),
+		ctx:    context.Background(),
+		cursor: 0,
+		errors: make([]*error, 0),
 	}
+	p.cond = sync.NewCond(&p.lock)
+	return p
+}
+
+// Resolve 新建已完成的 Promise (结果作为下一步入参)
+func Resolve(results ...any) *Promise {
+	p := NewPromise()
+	p.results = results
+	return p
 }
 
 // Apply 应用 Promise 配置
 func (p *Promise) Apply(config *PromiseConfig) *Promise {
+	p.lock.Lock()
+	defer p.lock.Unlock()
 	p.config = config
+	if config != nil && config.Context != nil {
+		p.ctx = config.Context
+	}
 	return p
 }
 
-// Then 异步执行下一步方法
+// Then 异步执行下一步方法 (传入多个 Action 时并行执行, 结果按顺序合并)
 func (p *Promise) Then(actions ...Action) *Promise {
 	if p.shouldIgnore(actions) {
 		return p
 	}
-	a := make([]*Action, 0)
-	for _, action := range actions {
-		a = append(a, &action)
+	a := make([]*Action, 0, len(actions))
+	for i := range actions {
+		a = append(a, &actions[i])
 	}
-	p.actions = append(p.actions, a)
-	go p.do()
-	return p
+	return p.push(&stage{kind: thenStage, actions: a})
+}
+
+// Catch 错误处理 (处理后错误清除, 处理方法返回值作为下一步入参)
+func (p *Promise) Catch(fn func(err error) []any) *Promise {
+	if fn == nil {
+		return p
+	}
+	return p.push(&stage{kind: catchStage, catch: fn})
+}
+
+// Finally 最终处理 (无论成功失败均执行, 不影响执行结果)
+func (p *Promise) Finally(fn func()) *Promise {
+	if fn == nil {
+		return p
+	}
+	return p.push(&stage{kind: finallyStage, finally: fn})
 }
 
 // shouldIgnore 忽略无效 Action
@@ -78,53 +158,200 @@ func (*Promise) shouldIgnore(actions []Action) bool {
 	return actions == nil || len(actions) == 0
 }
 
-// do 执行方法
-func (p *Promise) do() *Promise {
-	if len(p.actions[p.cursor]) > 1 {
-		return p.async()
+// push 添加执行阶段, 未执行时启动执行协程
+func (p *Promise) push(s *stage) *Promise {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.stages = append(p.stages, s)
+	if !p.running {
+		p.running = true
+		go p.do()
+	}
+	return p
+}
+
+// do 按顺序执行所有阶段
+func (p *Promise) do() {
+	for {
+		p.lock.Lock()
+		if int(p.cursor) >= len(p.stages) {
+			p.running = false
+			p.cond.Broadcast()
+			p.lock.Unlock()
+			return
+		}
+		s := p.stages[p.cursor]
+		prev, err, ctx := p.results, p.err, p.ctx
+		p.lock.Unlock()
+
+		res, stageErr, handled := p.execute(ctx, s, prev, err)
+
+		p.lock.Lock()
+		switch {
+		case stageErr != nil:
+			p.results, p.err = nil, stageErr
+			e := stageErr
+			p.errors = append(p.errors, &e)
+		case handled:
+			p.results, p.err = res, nil
+		}
+		p.cursor++
+		p.lock.Unlock()
 	}
-	return p.sync()
+}
+
+// execute 执行阶段
+// 返回: 执行结果, 本阶段产生的错误, 是否更新结果
+func (p *Promise) execute(ctx context.Context, s *stage, prev []any, err error) ([]any, error, bool) {
+	switch s.kind {
+	case thenStage:
+		if err != nil {
+			return nil, nil, false
+		}
+		if e := ctx.Err(); e != nil {
+			return nil, e, false
+		}
+		if len(s.actions) > 1 {
+			res, e := p.async(s.actions, prev)
+			return res, e, true
+		}
+		res, e := p.sync(s.actions[0], prev)
+		return res, e, true
+	case catchStage:
+		if err == nil {
+			return nil, nil, false
+		}
+		return safeCatch(s.catch, err)
+	case finallyStage:
+		return nil, safeFinally(s.finally), false
+	}
+	return nil, nil, false
+}
+
+// safeCatch 执行错误处理方法 (panic 转换为 PanicError)
+func safeCatch(fn func(error) []any, err error) (res []any, e error, handled bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			res, e, handled = nil, &PanicError{Value: r, Stack: debug.Stack()}, false
+		}
+	}()
+	return fn(err), nil, true
+}
+
+// safeFinally 执行最终处理方法 (panic 转换为 PanicError)
+func safeFinally(fn func()) (e error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	fn()
+	return nil
 }
 
 // sync 同步执行方法
-func (p *Promise) sync() *Promise {
-	current := p.actions[p.cursor]
-	if len(current) == 1 {
-		a := current[0]
-		if ok, _ := a.Valid(); ok {
-			fn := reflect.ValueOf(a.Fn)
-			resultList := fn.Call(convertParams(a.Args))
-			p.ctx = context.WithValue(p.ctx, "", convertResult(resultList))
+func (p *Promise) sync(action *Action, prev []any) ([]any, error) {
+	return action.call(prev)
+}
+
+// async 并行执行方法 (结果按 Action 顺序合并, 返回第一个失败 Action 的错误)
+func (p *Promise) async(actions []*Action, prev []any) ([]any, error) {
+	results := make([][]any, len(actions))
+	errs := make([]error, len(actions))
+	var wg sync.WaitGroup
+	wg.Add(len(actions))
+	for i, action := range actions {
+		go func(i int, action *Action) {
+			defer wg.Done()
+			results[i], errs[i] = action.call(prev)
+		}(i, action)
+	}
+	wg.Wait()
+	res := make([]any, 0)
+	for i := range actions {
+		if errs[i] != nil {
+			return nil, errs[i]
 		}
+		res = append(res, results[i]...)
 	}
-	p.cursor++
-	return p
+	return res, nil
 }
 
-// convertParams 参数转换
-func convertParams(args []any) []reflect.Value {
-	res := make([]reflect.Value, 0)
-	for _, arg := range args {
-		res = append(res, reflect.ValueOf(arg))
+// Get 同步等待获取结果 (等待当前已注册阶段执行完毕)
+func (p *Promise) Get() ([]any, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for p.running {
+		p.cond.Wait()
+	}
+	return p.results, p.err
+}
+
+// Errors 获取执行过程中产生的全部错误 (含已处理错误)
+func (p *Promise) Errors() []error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	res := make([]error, 0, len(p.errors))
+	for _, err := range p.errors {
+		res = append(res, *err)
 	}
 	return res
 }
 
-// convertResult 返回值转换
-func convertResult(res []reflect.Value) []any {
-	r := make([]any, 0)
-	for _, rd := range res {
-		r = append(r, rd.Interface())
+// buildParams 构建函数入参 (上一步结果按剩余入参个数截取 + 本次入参)
+func buildParams(t reflect.Type, prev []any, args []any) ([]reflect.Value, error) {
+	n := t.NumIn()
+	if t.IsVariadic() {
+		n = len(prev) + len(args)
+	} else if free := n - len(args); free < len(prev) {
+		if free < 0 {
+			return nil, fmt.Errorf("async: too many arguments for %v", t)
+		}
+		prev = prev[:free]
+	}
+	all := append(append(make([]any, 0, len(prev)+len(args)), prev...), args...)
+	if !t.IsVariadic() && len(all) != n {
+		return nil, fmt.Errorf("async: %v expects %d arguments, got %d", t, n, len(all))
 	}
-	return r
+	return convertParams(t, all), nil
 }
 
-// async 异步执行方法
-func (p *Promise) async() *Promise {
-	return p
+// convertParams 参数转换 (nil 转换为对应类型零值)
+func convertParams(t reflect.Type, args []any) []reflect.Value {
+	res := make([]reflect.Value, 0, len(args))
+	for i, arg := range args {
+		v := reflect.ValueOf(arg)
+		if !v.IsValid() {
+			v = reflect.Zero(paramType(t, i))
+		}
+		res = append(res, v)
+	}
+	return res
 }
 
-// Get 同步等待获取结果
-func (*Promise) Get() {
+// paramType 获取第 i 个入参类型
+func paramType(t reflect.Type, i int) reflect.Type {
+	if t.IsVariadic() && i >= t.NumIn()-1 {
+		return t.In(t.NumIn() - 1).Elem()
+	}
+	return t.In(i)
+}
 
+// convertResult 返回值转换 (最后一个返回值为 error 时作为执行错误)
+func convertResult(t reflect.Type, res []reflect.Value) ([]any, error) {
+	var err error
+	if n := t.NumOut(); n > 0 && t.Out(n-1) == errorType {
+		if e := res[n-1]; !e.IsNil() {
+			err = e.Interface().(error)
+		}
+		res = res[:n-1]
+	}
+	if err != nil {
+		return nil, err
+	}
+	r := make([]any, 0, len(res))
+	for _, rd := range res {
+		r = append(r, rd.Interface())
+	}
+	return r, nil
 }
@@ -0,0 +1,459 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/base"
+)
+
+var (
+	ErrSchedulerClosed = errors.New("async: scheduler is closed")                // 调度器已关闭
+	ErrInvalidInterval = errors.New("async: schedule interval must be positive") // 调度间隔非法
+)
+
+// OverlapPolicy 重叠策略 (到达执行时间时上一次执行尚未结束)
+type OverlapPolicy int
+
+const (
+	OverlapSkip     OverlapPolicy = iota // 跳过本次执行 (默认)
+	OverlapQueue                         // 排队, 上一次执行结束后依次执行
+	OverlapParallel                      // 并行执行
+)
+
+// MisfirePolicy 错过执行策略 (实际触发时间晚于计划时间超过阈值, 如进程挂起、时钟跳变)
+type MisfirePolicy int
+
+const (
+	MisfireRunOnce MisfirePolicy = iota // 立即补执行一次, 之后按计划继续 (默认)
+	MisfireSkip                         // 跳过错过的执行
+	MisfireRunAll                       // 逐次补执行全部错过的执行 (串行排队执行, OverlapSkip 时也不跳过; OverlapParallel 时并行)
+)
+
+// JobFn 调度任务方法 (任务取消或调度器关闭时 ctx 被取消)
+type JobFn func(ctx context.Context) error
+
+// JobConfig 调度任务配置
+type JobConfig struct {
+	Name             string        // 任务名 (默认 job-<id>)
+	Overlap          OverlapPolicy // 重叠策略 (固定延迟任务不会重叠)
+	Misfire          MisfirePolicy // 错过执行策略
+	MisfireThreshold time.Duration // 错过执行判定阈值 (默认 1s)
+	Jitter           time.Duration // 随机延迟上限 (每次执行在计划时间后随机延迟 [0, Jitter))
+}
+
+// SchedulerConfig Scheduler 配置
+type SchedulerConfig struct {
+	Clock        Clock                     // 时钟 (默认 RealClock, 测试时可使用 FakeClock)
+	Location     *time.Location            // cron 表达式默认时区 (默认 time.Local)
+	ErrorHandler func(job *Job, err error) // 任务错误处理钩子 (panic 转换为 PanicError)
+}
+
+// JobStats 调度任务统计信息
+type JobStats struct {
+	Runs     int64 // 已执行次数 (含失败)
+	Failures int64 // 失败次数 (含 panic)
+	Skipped  int64 // 因重叠跳过次数
+	Misfires int64 // 错过执行次数
+	Running  int64 // 执行中数量
+	Queued   int64 // 排队中数量
+}
+
+// Job 调度任务
+type Job struct {
+	id         int64              // 任务 id
+	name       string             // 任务名
+	scheduler  *Scheduler         // 所属调度器
+	fn         JobFn              // 任务方法
+	conf       *JobConfig         // 配置
+	schedule   Schedule           // 调度计划
+	fixedDelay time.Duration      // 固定延迟 (> 0 时为固定延迟任务)
+	ctx        context.Context    // 任务上下文
+	cancel     context.CancelFunc // 取消任务
+	done       chan struct{}      // 调度结束信号
+	execs      sync.WaitGroup     // 执行中任务计数
+	lock       sync.Mutex         // 状态锁
+	next       time.Time          // 下一次计划执行时间
+	running    int64              // 执行中数量
+	queued     int64              // 排队中数量
+	runs       int64              // 已执行次数
+	failures   int64              // 失败次数
+	skipped    int64              // 因重叠跳过次数
+	misfires   int64              // 错过执行次数
+}
+
+// ID 任务 id
+func (j *Job) ID() int64 {
+	return j.id
+}
+
+// Name 任务名
+func (j *Job) Name() string {
+	return j.name
+}
+
+// Cancel 取消任务 (不再调度, 执行中任务的 ctx 被取消)
+func (j *Job) Cancel() {
+	j.cancel()
+}
+
+// Done 调度结束信号 (任务取消、调度器关闭或调度计划结束)
+func (j *Job) Done() <-chan struct{} {
+	return j.done
+}
+
+// NextRun 下一次计划执行时间 (调度结束后返回零值)
+func (j *Job) NextRun() time.Time {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.next
+}
+
+// Stats 获取统计信息
+func (j *Job) Stats() JobStats {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return JobStats{
+		Runs:     atomic.LoadInt64(&j.runs),
+		Failures: atomic.LoadInt64(&j.failures),
+		Skipped:  atomic.LoadInt64(&j.skipped),
+		Misfires: atomic.LoadInt64(&j.misfires),
+		Running:  j.running,
+		Queued:   j.queued,
+	}
+}
+
+// Scheduler 延时/周期任务调度器
+type Scheduler struct {
+	conf   *SchedulerConfig   // 配置
+	clock  Clock              // 时钟
+	ctx    context.Context    // 调度器上下文
+	cancel context.CancelFunc // 关闭调度器
+	lock   sync.Mutex         // 状态锁
+	jobs   map[int64]*Job     // 调度中任务
+	seq    int64              // 任务 id 序列
+	closed bool               // 是否已关闭
+	loops  sync.WaitGroup     // 调度协程计数
+	runs   sync.WaitGroup     // 执行协程计数
+}
+
+// NewScheduler 新建调度器 (conf 为 nil 时使用默认配置)
+func NewScheduler(conf *SchedulerConfig) *Scheduler {
+	if conf == nil {
+		conf = &SchedulerConfig{}
+	}
+	if conf.Clock == nil {
+		conf.Clock = RealClock
+	}
+	if conf.Location == nil {
+		conf.Location = time.Local
+	}
+	s := &Scheduler{conf: conf, clock: conf.Clock, jobs: make(map[int64]*Job)}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return s
+}
+
+// After 延迟 delay 后执行一次
+func (s *Scheduler) After(delay time.Duration, fn JobFn, conf *JobConfig) (*Job, error) {
+	return s.At(s.clock.Now().Add(delay), fn, conf)
+}
+
+// At 在指定时间执行一次 (时间已过时按错过执行策略处理)
+func (s *Scheduler) At(at time.Time, fn JobFn, conf *JobConfig) (*Job, error) {
+	return s.add(&onceSchedule{at: at}, 0, at, fn, conf)
+}
+
+// Every 固定频率执行 (首次在 interval 后执行, 执行时间按计划累加, 不受执行耗时影响)
+func (s *Scheduler) Every(interval time.Duration, fn JobFn, conf *JobConfig) (*Job, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	return s.add(Every(interval), 0, s.clock.Now().Add(interval), fn, conf)
+}
+
+// EveryDelay 固定延迟执行 (上一次执行结束 delay 后再执行下一次)
+func (s *Scheduler) EveryDelay(delay time.Duration, fn JobFn, conf *JobConfig) (*Job, error) {
+	if delay <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	return s.add(nil, delay, s.clock.Now().Add(delay), fn, conf)
+}
+
+// Cron 按 cron 表达式执行 (表达式语法见 ParseCron, 未指定时区时使用配置时区)
+func (s *Scheduler) Cron(expr string, fn JobFn, conf *JobConfig) (*Job, error) {
+	sched, err := ParseCronInLocation(expr, s.conf.Location)
+	if err != nil {
+		return nil, err
+	}
+	return s.Schedule(sched, fn, conf)
+}
+
+// Schedule 按自定义调度计划执行
+func (s *Scheduler) Schedule(sched Schedule, fn JobFn, conf *JobConfig) (*Job, error) {
+	return s.add(sched, 0, sched.Next(s.clock.Now()), fn, conf)
+}
+
+// Jobs 获取调度中任务 (按 id 排序)
+func (s *Scheduler) Jobs() []*Job {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	res := make([]*Job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		res = append(res, j)
+	}
+	sort.Slice(res, func(i, k int) bool { return res[i].id < res[k].id })
+	return res
+}
+
+// Shutdown 关闭调度器 (停止调度并取消执行中任务的 ctx, 等待执行中任务返回; 上下文取消时返回 ctx.Err())
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.lock.Lock()
+	s.closed = true
+	s.lock.Unlock()
+	s.cancel()
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		s.runs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// add 登记任务并启动调度协程
+func (s *Scheduler) add(sched Schedule, delay time.Duration, first time.Time, fn JobFn, conf *JobConfig) (*Job, error) {
+	if fn == nil {
+		return nil, ErrNilTask
+	}
+	if conf == nil {
+		conf = &JobConfig{}
+	}
+	conf.MisfireThreshold = base.SetOrDefault(conf.MisfireThreshold, time.Second)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil, ErrSchedulerClosed
+	}
+	s.seq++
+	j := &Job{
+		id:         s.seq,
+		name:       conf.Name,
+		scheduler:  s,
+		fn:         fn,
+		conf:       conf,
+		schedule:   sched,
+		fixedDelay: delay,
+		done:       make(chan struct{}),
+		next:       first,
+	}
+	if j.name == "" {
+		j.name = fmt.Sprintf("job-%d", j.id)
+	}
+	j.ctx, j.cancel = context.WithCancel(s.ctx)
+	s.jobs[j.id] = j
+	s.loops.Add(1)
+	go j.loop(first)
+	return j, nil
+}
+
+// loop 任务调度主循环
+func (j *Job) loop(scheduled time.Time) {
+	s := j.scheduler
+	defer func() {
+		j.setNext(time.Time{})
+		s.lock.Lock()
+		delete(s.jobs, j.id)
+		s.lock.Unlock()
+		// 执行中任务结束后释放任务上下文
+		go func() {
+			j.execs.Wait()
+			j.cancel()
+		}()
+		close(j.done)
+		s.loops.Done()
+	}()
+	for !scheduled.IsZero() {
+		j.setNext(scheduled)
+		fireAt := scheduled.Add(j.jitter())
+		if !j.wait(fireAt) {
+			return
+		}
+		// 固定延迟: 同步执行, 结束后计算下一次执行时间
+		if j.fixedDelay > 0 {
+			j.acquire()
+			j.execs.Add(1)
+			j.execute()
+			j.execs.Done()
+			j.release()
+			scheduled = s.clock.Now().Add(j.fixedDelay)
+			continue
+		}
+		now := s.clock.Now()
+		if now.Sub(fireAt) <= j.conf.MisfireThreshold {
+			j.dispatch()
+			scheduled = j.schedule.Next(scheduled)
+			continue
+		}
+		atomic.AddInt64(&j.misfires, 1)
+		switch j.conf.Misfire {
+		case MisfireSkip:
+		case MisfireRunAll:
+			n := 0
+			for t := scheduled; !t.IsZero() && !t.After(now); t = j.schedule.Next(t) {
+				n++
+			}
+			j.catchUp(n)
+		default:
+			j.dispatch()
+		}
+		scheduled = j.nextAfter(scheduled, now)
+	}
+}
+
+// nextAfter 计算 now 之后的下一次计划执行时间 (固定频率任务保持原有相位)
+func (j *Job) nextAfter(prev, now time.Time) time.Time {
+	if rs, ok := j.schedule.(*rateSchedule); ok {
+		return prev.Add((now.Sub(prev)/rs.interval + 1) * rs.interval)
+	}
+	return j.schedule.Next(now)
+}
+
+// setNext 更新下一次计划执行时间
+func (j *Job) setNext(t time.Time) {
+	j.lock.Lock()
+	j.next = t
+	j.lock.Unlock()
+}
+
+// jitter 随机延迟
+func (j *Job) jitter() time.Duration {
+	if j.conf.Jitter <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(j.conf.Jitter)))
+}
+
+// wait 等待到达执行时间 (任务取消时返回 false)
+func (j *Job) wait(at time.Time) bool {
+	timer := j.scheduler.clock.NewTimer(at.Sub(j.scheduler.clock.Now()))
+	select {
+	case <-timer.C():
+		return true
+	case <-j.ctx.Done():
+		timer.Stop()
+		return false
+	}
+}
+
+// dispatch 按重叠策略执行任务
+func (j *Job) dispatch() {
+	j.lock.Lock()
+	if j.running > 0 {
+		switch j.conf.Overlap {
+		case OverlapQueue:
+			j.queued++
+			j.lock.Unlock()
+			return
+		case OverlapSkip:
+			j.lock.Unlock()
+			atomic.AddInt64(&j.skipped, 1)
+			return
+		}
+	}
+	j.running++
+	j.lock.Unlock()
+	j.run()
+}
+
+// catchUp 补执行 n 次错过的执行 (除 OverlapParallel 外均串行排队执行)
+func (j *Job) catchUp(n int) {
+	if j.conf.Overlap == OverlapParallel {
+		for i := 0; i < n; i++ {
+			j.dispatch()
+		}
+		return
+	}
+	j.lock.Lock()
+	if j.running > 0 {
+		j.queued += int64(n)
+		j.lock.Unlock()
+		return
+	}
+	j.running++
+	j.queued += int64(n - 1)
+	j.lock.Unlock()
+	j.run()
+}
+
+// run 启动执行协程 (需已登记执行中任务), 执行结束后依次执行排队任务
+func (j *Job) run() {
+	j.scheduler.runs.Add(1)
+	j.execs.Add(1)
+	go func() {
+		defer j.scheduler.runs.Done()
+		defer j.execs.Done()
+		j.execute()
+		// 排队策略: 依次执行排队任务
+		for {
+			j.lock.Lock()
+			if j.queued == 0 || j.ctx.Err() != nil {
+				j.queued = 0
+				j.running--
+				j.lock.Unlock()
+				return
+			}
+			j.queued--
+			j.lock.Unlock()
+			j.execute()
+		}
+	}()
+}
+
+// acquire 登记执行中任务
+func (j *Job) acquire() {
+	j.lock.Lock()
+	j.running++
+	j.lock.Unlock()
+}
+
+// release 注销执行中任务
+func (j *Job) release() {
+	j.lock.Lock()
+	j.running--
+	j.lock.Unlock()
+}
+
+// execute 执行任务 (panic 转换为 PanicError, 错误交由 ErrorHandler 处理)
+func (j *Job) execute() {
+	err := j.call()
+	atomic.AddInt64(&j.runs, 1)
+	if err == nil {
+		return
+	}
+	atomic.AddInt64(&j.failures, 1)
+	if h := j.scheduler.conf.ErrorHandler; h != nil {
+		h(j, err)
+	}
+}
+
+// call 调用任务方法
+func (j *Job) call() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return j.fn(j.ctx)
+}
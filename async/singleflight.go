@@ -0,0 +1,160 @@
+package async
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// SingleFlightConfig SingleFlight 配置
+type SingleFlightConfig struct {
+	TTL      time.Duration // 成功结果缓存时间 (0 不缓存)
+	ErrorTTL time.Duration // 错误结果缓存时间 (默认 0, 出错后立即遗忘, 下次调用重新执行)
+	Clock    Clock         // 时钟 (默认 RealClock)
+}
+
+// flightCall 执行中的调用
+type flightCall[V any] struct {
+	done    chan struct{}      // 完成信号
+	cancel  context.CancelFunc // 取消执行 (全部调用方放弃等待时)
+	waiters int                // 等待中的调用方数
+	dups    int                // 合并的重复调用数
+	value   V                  // 结果
+	err     error              // 错误
+}
+
+// flightEntry 缓存结果
+type flightEntry[V any] struct {
+	value  V         // 结果
+	err    error     // 错误
+	expire time.Time // 过期时间
+}
+
+// SingleFlight 合并相同 key 的并发调用 (可选缓存结果)
+type SingleFlight[K comparable, V any] struct {
+	conf  *SingleFlightConfig   // 配置
+	lock  sync.Mutex            // 状态锁
+	calls map[K]*flightCall[V]  // 执行中的调用
+	cache map[K]*flightEntry[V] // 缓存结果
+}
+
+// NewSingleFlight 新建 SingleFlight (conf 为 nil 时不缓存结果)
+func NewSingleFlight[K comparable, V any](conf *SingleFlightConfig) *SingleFlight[K, V] {
+	if conf == nil {
+		conf = &SingleFlightConfig{}
+	}
+	if conf.Clock == nil {
+		conf.Clock = RealClock
+	}
+	return &SingleFlight[K, V]{
+		conf:  conf,
+		calls: make(map[K]*flightCall[V]),
+		cache: make(map[K]*flightEntry[V]),
+	}
+}
+
+// Do 执行调用 (相同 key 的并发调用只执行一次, 结果共享)
+// shared 表示结果是否与其他调用方共享 (含缓存命中);
+// ctx 取消时当前调用方立即返回 ctx.Err(), 全部调用方均放弃等待时取消执行方法的 ctx
+// (执行方法的 ctx 保留第一个调用方 ctx 中的值, 但不继承其取消信号)
+func (g *SingleFlight[K, V]) Do(ctx context.Context, key K, fn func(ctx context.Context) (V, error)) (v V, shared bool, err error) {
+	g.lock.Lock()
+	if e, ok := g.cache[key]; ok {
+		if g.conf.Clock.Now().Before(e.expire) {
+			g.lock.Unlock()
+			return e.value, true, e.err
+		}
+		delete(g.cache, key)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.waiters++
+		c.dups++
+		g.lock.Unlock()
+		return g.wait(ctx, key, c)
+	}
+	c := &flightCall[V]{done: make(chan struct{}), waiters: 1}
+	callCtx, cancel := context.WithCancel(detachedContext{ctx})
+	c.cancel = cancel
+	g.calls[key] = c
+	g.lock.Unlock()
+	go g.run(callCtx, key, c, fn)
+	return g.wait(ctx, key, c)
+}
+
+// Forget 遗忘 key (删除缓存, 之后的调用不再合并到执行中的调用)
+func (g *SingleFlight[K, V]) Forget(key K) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	delete(g.calls, key)
+	delete(g.cache, key)
+}
+
+// run 执行方法并记录结果 (panic 转换为 PanicError)
+func (g *SingleFlight[K, V]) run(ctx context.Context, key K, c *flightCall[V], fn func(ctx context.Context) (V, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+		c.cancel()
+		g.lock.Lock()
+		if g.calls[key] == c {
+			delete(g.calls, key)
+			ttl := g.conf.TTL
+			if c.err != nil {
+				ttl = g.conf.ErrorTTL
+			}
+			if ttl > 0 {
+				g.cache[key] = &flightEntry[V]{value: c.value, err: c.err, expire: g.conf.Clock.Now().Add(ttl)}
+			}
+		}
+		g.lock.Unlock()
+		close(c.done)
+	}()
+	c.value, c.err = fn(ctx)
+}
+
+// wait 等待调用结果
+func (g *SingleFlight[K, V]) wait(ctx context.Context, key K, c *flightCall[V]) (V, bool, error) {
+	select {
+	case <-c.done:
+		return c.value, c.dups > 0, c.err
+	case <-ctx.Done():
+		g.lock.Lock()
+		defer g.lock.Unlock()
+		c.waiters--
+		if c.waiters == 0 {
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		var zero V
+		return zero, c.dups > 0, ctx.Err()
+	}
+}
+
+// detachedContext 保留父上下文的值但不继承其取消信号及截止时间
+type detachedContext struct {
+	parent context.Context
+}
+
+// Deadline 无截止时间
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done 不会被取消
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err 无错误
+func (detachedContext) Err() error {
+	return nil
+}
+
+// Value 获取父上下文的值
+func (c detachedContext) Value(key any) any {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,19 @@
+package error
+
+import "strings"
+
+// RedirectRejected 重定向被拒绝错误
+type RedirectRejected struct {
+	name    string
+	Details string
+}
+
+func (err *RedirectRejected) Error() string {
+	var builder strings.Builder
+	builder.WriteString("Redirect rejected")
+	if err.Details != "" {
+		builder.WriteString(" - ")
+		builder.WriteString(err.Details)
+	}
+	return builder.String()
+}
@@ -6,6 +6,7 @@ import "strings"
 type RequestFail struct {
 	name    string
 	Details string
+	Cause   error // 原始错误 (如 problem+json 问题详情)
 }
 
 func (err *RequestFail) Error() string {
@@ -17,3 +18,8 @@ func (err *RequestFail) Error() string {
 	}
 	return builder.String()
 }
+
+// Unwrap 获取原始错误
+func (err *RequestFail) Unwrap() error {
+	return err.Cause
+}
@@ -0,0 +1,418 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrDispatcherClosed = errors.New("flow: dispatcher is closed")           // 分发器已关闭
+	ErrOutboundExists   = errors.New("flow: outbound flow already exists")   // 输出流已存在
+	ErrOutboundNotFound = errors.New("flow: outbound flow not found")        // 输出流不存在
+	ErrOutboundClosed   = errors.New("flow: outbound flow is not started")   // 输出流未开启
+	ErrNoRoute          = errors.New("flow: no outbound flow for item")      // 无可用输出流
+	ErrDispatchDropped  = errors.New("flow: item dropped by backpressure")   // 输出流缓冲已满, 数据被丢弃
+	ErrKeyFnRequired    = errors.New("flow: hash strategy requires a KeyFn") // 哈希分发需要 KeyFn (新建分发器时 panic)
+)
+
+// 分发策略常量枚举
+const (
+	RouteRoundRobin = iota // 轮询分发
+	RouteBroadcast         // 广播分发
+	RouteHash              // 按 key 哈希分发 (相同 key 始终进入同一输出流)
+)
+
+// DispatcherConfig 数据分发器配置
+type DispatcherConfig[T any] struct {
+	Strategy     int                                // 分发策略 [RouteRoundRobin:轮询, RouteBroadcast:广播, RouteHash:哈希; 默认轮询]
+	KeyFn        func(item T) string                // 数据 key (用于路由表匹配及哈希分发)
+	StrictRoute  bool                               // 严格路由 (未匹配路由表的数据直接丢弃, 否则分发到全部输出流)
+	BlockTimeout time.Duration                      // 输出流缓冲满时最长阻塞时间 (0: 一直阻塞, 向上游施加背压; <0: 不阻塞直接丢弃)
+	OnDrop       func(item T, id string, err error) // 数据丢弃回调 (id 为目标输出流 ID, 无可用输出流时为空)
+}
+
+// outbound 输出流
+type outbound[T any] struct {
+	id     string         // 输出流 ID
+	data   chan T         // 输出流数据管道
+	lock   sync.Mutex     // 容量检查锁 (不阻塞分发时保证检查与写入转发管道的原子性)
+	queued int64          // 已写入转发管道、尚未写入输出流数据管道的数据数
+	sends  sync.WaitGroup // 进行中的发送 (分发器关闭后转发生产方等待其结束)
+	ch     chan T         // 转发管道 (缓冲大小与输出流一致, 由输出流上的转发生产方读取)
+	quit   chan struct{}  // 移除信号 (放弃转发中的数据)
+	done   chan struct{}  // 关闭信号 (转发完已接收的数据后退出)
+	exit   chan struct{}  // 转发生产方退出信号 (输出流运行上下文取消时提前退出)
+}
+
+// DataDispatcher 数据分发器
+// 输出流通过 AddOutBound 注册, 分发器在每个输出流上注册一个转发生产方, 输出流在分发器关闭或移除前不会结束;
+// 转发生产方直接写入输出流的数据管道 (不经过输出流的溢出策略, 背压由 BlockTimeout 控制)
+type DataDispatcher[T any] struct {
+	conf      *DispatcherConfig[T]      // 配置
+	lock      sync.RWMutex              // 路由表锁
+	outbounds map[string]*outbound[T]   // 输出流表 map[Flow ID]outbound
+	ordered   []*outbound[T]            // 按 ID 排序的输出流 (保证哈希分发稳定)
+	routes    map[string][]*outbound[T] // 路由表 map[key][]outbound
+	inbounds  int                       // 绑定的输入流数
+	closed    bool                      // 是否已关闭
+	cursor    uint64                    // 轮询游标
+	dropped   int64                     // 丢弃计数
+}
+
+// NewDataDispatcher 新建数据分发器 (conf 为 nil 时使用默认配置; 哈希分发未配置 KeyFn 时 panic)
+func NewDataDispatcher[T any](conf *DispatcherConfig[T]) *DataDispatcher[T] {
+	res := &DispatcherConfig[T]{}
+	if conf != nil {
+		*res = *conf
+	}
+	if res.Strategy == RouteHash && res.KeyFn == nil {
+		panic(ErrKeyFnRequired.Error())
+	}
+	return &DataDispatcher[T]{
+		conf:      res,
+		outbounds: make(map[string]*outbound[T]),
+		routes:    make(map[string][]*outbound[T]),
+	}
+}
+
+// AddOutBound 添加输出流 (输出流需处于开启状态)
+func (d *DataDispatcher[T]) AddOutBound(flow *DataFlow[T]) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+	if _, ok := d.outbounds[flow.ID]; ok {
+		return ErrOutboundExists
+	}
+	o := &outbound[T]{
+		id:   flow.ID,
+		data: flow.DataChannel,
+		ch:   make(chan T, cap(flow.DataChannel)),
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+		exit: make(chan struct{}),
+	}
+	// 注册转发生产方: 输出流关闭时等待其退出
+	if !flow.tryProduce(func(ctx context.Context, _ chan<- T, ec chan<- error) error {
+		defer close(o.exit)
+		for {
+			select {
+			case v := <-o.ch:
+				if !o.forward(ctx, flow, v) {
+					return nil
+				}
+			case <-o.quit:
+				return nil
+			case <-o.done:
+				// 等待关闭前已开始的发送结束 (关闭后发送不再阻塞), 转发完已接收的数据后退出
+				o.sends.Wait()
+				for {
+					select {
+					case v := <-o.ch:
+						if !o.forward(ctx, flow, v) {
+							return nil
+						}
+					default:
+						return nil
+					}
+				}
+			case <-ctx.Done():
+				return nil
+			}
+		}
+	}) {
+		return ErrOutboundClosed
+	}
+	d.outbounds[o.id] = o
+	d.ordered = append(d.ordered, o)
+	sort.Slice(d.ordered, func(i, j int) bool { return d.ordered[i].id < d.ordered[j].id })
+	return nil
+}
+
+// RemoveOutBound 移除输出流 (同时移除其全部路由, 释放输出流上的转发生产方)
+func (d *DataDispatcher[T]) RemoveOutBound(id string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	o, ok := d.outbounds[id]
+	if !ok {
+		return ErrOutboundNotFound
+	}
+	delete(d.outbounds, id)
+	d.ordered = removeOutbound(d.ordered, o)
+	for key, list := range d.routes {
+		if list = removeOutbound(list, o); len(list) == 0 {
+			delete(d.routes, key)
+		} else {
+			d.routes[key] = list
+		}
+	}
+	close(o.quit)
+	return nil
+}
+
+// OutBounds 输出流 ID 列表 (按 ID 排序)
+func (d *DataDispatcher[T]) OutBounds() []string {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	ids := make([]string, 0, len(d.ordered))
+	for _, o := range d.ordered {
+		ids = append(ids, o.id)
+	}
+	return ids
+}
+
+// AddRoute 添加路由规则 (key 为 KeyFn 返回值, 匹配的数据只分发到路由的输出流)
+func (d *DataDispatcher[T]) AddRoute(key string, outboundIDs ...string) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	list := d.routes[key]
+	for _, id := range outboundIDs {
+		o, ok := d.outbounds[id]
+		if !ok {
+			return ErrOutboundNotFound
+		}
+		if !containsOutbound(list, o) {
+			list = append(list, o)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].id < list[j].id })
+	d.routes[key] = list
+	return nil
+}
+
+// RemoveRoute 移除路由规则
+func (d *DataDispatcher[T]) RemoveRoute(key, outboundID string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	o, ok := d.outbounds[outboundID]
+	if !ok {
+		return
+	}
+	if list := removeOutbound(d.routes[key], o); len(list) == 0 {
+		delete(d.routes, key)
+	} else {
+		d.routes[key] = list
+	}
+}
+
+// ClearRoute 清除指定 key 的全部路由规则
+func (d *DataDispatcher[T]) ClearRoute(key string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.routes, key)
+}
+
+// ResetRoute 重置路由规则
+func (d *DataDispatcher[T]) ResetRoute() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.routes = make(map[string][]*outbound[T])
+}
+
+// Dropped 丢弃数据计数
+func (d *DataDispatcher[T]) Dropped() int64 {
+	return atomic.LoadInt64(&d.dropped)
+}
+
+// Dispatch 分发数据 (按路由表及分发策略选择输出流, 输出流缓冲满时按 BlockTimeout 阻塞或丢弃)
+func (d *DataDispatcher[T]) Dispatch(item T) error {
+	targets, err := d.targets(item)
+	if err != nil {
+		d.drop(item, "", err)
+		return err
+	}
+	var res error
+	for _, o := range targets {
+		err = d.send(o, item)
+		o.sends.Done()
+		if err != nil {
+			d.drop(item, o.id, err)
+			res = err
+		}
+	}
+	return res
+}
+
+// Close 关闭分发器, 转发完已接收的数据后释放全部输出流上的转发生产方
+func (d *DataDispatcher[T]) Close() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return
+	}
+	d.closed = true
+	for _, o := range d.outbounds {
+		close(o.done)
+	}
+	d.outbounds = make(map[string]*outbound[T])
+	d.ordered = nil
+	d.routes = make(map[string][]*outbound[T])
+}
+
+// bind 绑定输入流
+func (d *DataDispatcher[T]) bind() {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.inbounds++
+}
+
+// unbind 解绑输入流 (全部输入流解绑后关闭分发器)
+func (d *DataDispatcher[T]) unbind() {
+	d.lock.Lock()
+	d.inbounds--
+	last := d.inbounds == 0
+	d.lock.Unlock()
+	if last {
+		d.Close()
+	}
+}
+
+// targets 选择目标输出流 (目标输出流的进行中发送数加 1, 发送结束后减 1)
+func (d *DataDispatcher[T]) targets(item T) ([]*outbound[T], error) {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	if d.closed {
+		return nil, ErrDispatcherClosed
+	}
+	targets, err := d.route(item)
+	for _, o := range targets {
+		o.sends.Add(1)
+	}
+	return targets, err
+}
+
+// route 按路由表及分发策略选择目标输出流
+func (d *DataDispatcher[T]) route(item T) ([]*outbound[T], error) {
+	var key string
+	candidates := d.ordered
+	if d.conf.KeyFn != nil {
+		key = d.conf.KeyFn(item)
+		if list, ok := d.routes[key]; ok {
+			candidates = list
+		} else if d.conf.StrictRoute {
+			candidates = nil
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, ErrNoRoute
+	}
+	switch d.conf.Strategy {
+	case RouteBroadcast:
+		return append([]*outbound[T](nil), candidates...), nil
+	case RouteHash:
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key))
+		return []*outbound[T]{candidates[h.Sum32()%uint32(len(candidates))]}, nil
+	default:
+		n := atomic.AddUint64(&d.cursor, 1) - 1
+		return []*outbound[T]{candidates[n%uint64(len(candidates))]}, nil
+	}
+}
+
+// send 向输出流发送数据
+func (d *DataDispatcher[T]) send(o *outbound[T], item T) error {
+	timeout := d.conf.BlockTimeout
+	if timeout < 0 {
+		return o.trySend(item)
+	}
+	var expire <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expire = timer.C
+	}
+	atomic.AddInt64(&o.queued, 1)
+	select {
+	case o.ch <- item:
+		return nil
+	case <-o.quit:
+		atomic.AddInt64(&o.queued, -1)
+		return ErrOutboundNotFound
+	case <-o.done:
+		atomic.AddInt64(&o.queued, -1)
+		return ErrDispatcherClosed
+	case <-o.exit:
+		atomic.AddInt64(&o.queued, -1)
+		return ErrOutboundClosed
+	case <-expire:
+		atomic.AddInt64(&o.queued, -1)
+		return ErrDispatchDropped
+	}
+}
+
+// trySend 不阻塞发送 (输出流数据管道及转发中的数据占满输出流缓冲时丢弃; 无缓冲输出流仅在转发生产方空闲时写入)
+func (o *outbound[T]) trySend(item T) error {
+	select {
+	case <-o.quit:
+		return ErrOutboundNotFound
+	case <-o.done:
+		return ErrDispatcherClosed
+	case <-o.exit:
+		return ErrOutboundClosed
+	default:
+	}
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	if c := cap(o.data); c > 0 && len(o.data)+int(atomic.LoadInt64(&o.queued)) >= c {
+		return ErrDispatchDropped
+	}
+	// 有缓冲时已预留容量 (转发管道缓冲与输出流一致), 写入不会阻塞
+	atomic.AddInt64(&o.queued, 1)
+	select {
+	case o.ch <- item:
+		return nil
+	default:
+		atomic.AddInt64(&o.queued, -1)
+		return ErrDispatchDropped
+	}
+}
+
+// forward 将数据写入输出流数据管道 (输出流运行上下文取消或输出流被移除时返回 false)
+func (o *outbound[T]) forward(ctx context.Context, flow *DataFlow[T], v T) bool {
+	flow.metrics.received()
+	select {
+	case o.data <- v:
+		flow.metrics.deliver()
+		atomic.AddInt64(&o.queued, -1)
+		return true
+	case <-o.quit:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// drop 记录丢弃数据
+func (d *DataDispatcher[T]) drop(item T, id string, err error) {
+	atomic.AddInt64(&d.dropped, 1)
+	if d.conf.OnDrop != nil {
+		d.conf.OnDrop(item, id, err)
+	}
+}
+
+// removeOutbound 从列表中移除输出流 (返回新列表, 不修改原列表)
+func removeOutbound[T any](list []*outbound[T], o *outbound[T]) []*outbound[T] {
+	res := make([]*outbound[T], 0, len(list))
+	for _, v := range list {
+		if v != o {
+			res = append(res, v)
+		}
+	}
+	return res
+}
+
+// containsOutbound 列表中是否包含输出流
+func containsOutbound[T any](list []*outbound[T], o *outbound[T]) bool {
+	for _, v := range list {
+		if v == o {
+			return true
+		}
+	}
+	return false
+}
@@ -1,93 +1,20 @@
 package flow
 
 import (
-	"runtime"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
 	"sync/atomic"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
 )
 
-// todo 需要新增数据分发器 - 实现多个消费方分发
-
-//// DataDispatcher 数据分发器
-//type DataDispatcher[T any] struct {
-//	InOperationState  lock.CustomLock // 输入流操作锁
-//	OutOperationState lock.CustomLock // 输出流操作锁
-//	InBoundMap        sync.Map        // 输入流表 map[Flow ID]Flow
-//	OutBoundMap       sync.Map        // 输出流表 map[Flow ID]Flow
-//	RouteMap          sync.Map        // 路由表 map[InBound Flow ID][InBound Flow ID List]
-//	//InBounds          []*DataFlow[T] // 输入流
-//	//OutBounds         []*DataFlow[T] // 输出流
-//}
-//
-//// NewDataDispatcher 新建数据分发器
-//func NewDataDispatcher[T any]() *DataDispatcher[T] {
-//	return &DataDispatcher[T]{
-//		InBoundMap:  sync.Map{},
-//		OutBoundMap: sync.Map{},
-//		RouteMap:    sync.Map{},
-//		//InBounds:  make([]*DataFlow[T], 0),
-//		//OutBounds: make([]*DataFlow[T], 0),
-//	}
-//}
-//
-//// AddInBound 添加输入流
-//func (d *DataDispatcher[T]) AddInBound(flow *DataFlow[T]) *DataDispatcher[T] {
-//	d.InOperationState.Lock()
-//	defer d.InOperationState.Unlock()
-//
-//	//d.InBounds = append(d.InBounds, flow)
-//	return d
-//}
-//
-//// RemoveInBound 移除输入流
-//func (d *DataDispatcher[T]) RemoveInBound(flow *DataFlow[T]) *DataDispatcher[T] {
-//	d.InOperationState.Lock()
-//	defer d.InOperationState.Unlock()
-//	return d
-//}
-//
-//// AddOutBound 添加输出流
-//func (d *DataDispatcher[T]) AddOutBound(flow *DataFlow[T]) *DataDispatcher[T] {
-//	//d.OutBounds = append(d.OutBounds, flow)
-//	return d
-//}
-//
-//// RemoveOutBound 移除输出流
-//func (d *DataDispatcher[T]) RemoveOutBound(flow *DataFlow[T]) *DataDispatcher[T] {
-//	return d
-//}
-//
-//// AddRoute 添加路由规则
-//func (d *DataDispatcher[T]) AddRoute(inboundID, outboundID string) *DataDispatcher[T] {
-//	return d
-//}
-//
-//// RemoveRoute 移除路由规则
-//func (d *DataDispatcher[T]) RemoveRoute(inboundID, outboundID string) *DataDispatcher[T] {
-//	return d
-//}
-//
-//// ClearAllRouteWithInbound 清除所有指定输入流的路由规则
-//func (d *DataDispatcher[T]) ClearAllRouteWithInbound(inboundID string) *DataDispatcher[T] {
-//	return d
-//}
-//
-//// ClearAllRouteWithOutbound 清除所有指定输出流的路由规则
-//func (d *DataDispatcher[T]) ClearAllRouteWithOutbound(outboundID string) *DataDispatcher[T] {
-//	return d
-//}
-//
-//// ResetRoute 重置路由规则
-//func (d *DataDispatcher[T]) ResetRoute() *DataDispatcher[T] {
-//	return d
-//}
-
-// DataDispatcher 数据分发器
-type DataDispatcher struct {
-}
-
-func NewDataDispatcher() *DataDispatcher {
-	return &DataDispatcher{}
-}
+var flowSeq int64 // 数据流 ID 序号
+
+var ErrFlowClosed = errors.New("flow: data flow is closing or closed") // 数据流关闭中或已关闭
 
 // 数据流模式常量枚举
 const (
@@ -102,43 +29,110 @@ const (
 	StartedState        // 数据流开启状态
 )
 
+// FlowConfig 数据流配置
+type FlowConfig[T any] struct {
+	BufSize         uint                // 数据管道缓冲大小 (数据泵另持有一条写入中的数据, 阻塞策略下生产方最多可领先消费方 BufSize+1 条)
+	Overflow        int                 // 溢出策略 [OverflowBlock:阻塞, OverflowDropNewest:丢弃新数据, OverflowDropOldest:丢弃最旧数据, OverflowSpill:溢出到磁盘; 默认阻塞]
+	OnDrop          func(item T)        // 数据丢弃回调
+	SpillQueue      SpillQueue[T]       // 溢出队列 (为空时首次溢出在 SpillDir 下新建 FileSpill)
+	SpillDir        string              // 溢出文件目录 (默认系统临时目录)
+	Codec           Codec[T]            // 溢出数据编解码器 (默认 JSONCodec)
+	MetricsWindow   time.Duration       // 吞吐量滑动窗口 (默认 10s, 最小 10ms)
+	MetricsInterval time.Duration       // 指标导出间隔 (0: 仅在数据流关闭时导出)
+	OnMetrics       func(m FlowMetrics) // 指标导出回调
+	ManualStart     bool                // 手动开启 (新建后不自动开启, 需调用 Start 或 Run; 开启前注入的生产方在开启后开始写入)
+}
+
 // DataFlow 数据流
+// 生产方与消费方的错误先写入内部输入管道, 由数据泵按溢出策略转入 DataChannel / ErrChannel 并记录指标
+// 生命周期: 开启 (新建时自动开启, ManualStart 时由 Start/Run 开启) -> 关闭中 (Stop 或运行上下文取消, 禁止注册生产方) -> 生产方全部结束后关闭数据管道
+// -> 消费方消费完剩余数据 (OnDrained) -> 关闭错误管道 (OnClosed)
 type DataFlow[T any] struct {
-	ID             string          // 数据流 ID
-	DataChannel    chan T          // 数据管道
-	ErrChannel     chan error      // 错误管道
-	DataDispatcher *DataDispatcher // 数据分发器
-	Counter        int64           // 生产者计数器
-	State          int64           // 管道状态 [0:无生产者无消费者, 1:无生产者有消费者, 2:有生产者有消费者]
-	FlowMode       int32           // 数据流模式 [DataConsume:数据消费, DataDispatch:数据分发; 默认数据消费模式]
+	ID             string             // 数据流 ID
+	DataChannel    chan T             // 数据管道
+	ErrChannel     chan error         // 错误管道
+	DataDispatcher *DataDispatcher[T] // 数据分发器
+	Counter        int64              // 生产者计数器
+	Consumers      int64              // 消费者计数器
+	State          int64              // 管道状态 [ClosedState:关闭, ClosingState:关闭中, StartedState:开启]
+	FlowMode       int32              // 数据流模式 [DataConsume:数据消费, DataDispatch:数据分发; 默认数据消费模式]
+
+	conf      *FlowConfig[T]     // 配置
+	in        chan T             // 数据输入管道 (生产方写入)
+	errIn     chan error         // 错误输入管道 (生产方及消费方写入)
+	spill     SpillQueue[T]      // 溢出队列
+	metrics   *flowMetrics       // 指标计数器
+	lock      sync.Mutex         // 生命周期锁
+	ctx       context.Context    // 运行上下文 (传递给生产方, 关闭超时或外部取消时取消)
+	cancel    context.CancelFunc // 取消运行上下文
+	started   bool               // 是否已开启
+	stopping  bool               // 是否已开始关闭
+	inClosed  bool               // 数据管道是否已关闭
+	drained   bool               // 数据是否已排空 (数据管道已关闭且消费方全部结束)
+	produced  chan struct{}      // 生产方全部结束信号
+	consumed  chan struct{}      // 数据管道关闭后消费方全部结束信号
+	closed    chan struct{}      // 数据流关闭信号
+	pumped    chan struct{}      // 数据泵结束信号
+	errPumped chan struct{}      // 错误泵结束信号
+	onStart   []func()           // 开启钩子
+	onDrained []func()           // 排空钩子
+	onClosed  []func()           // 关闭钩子
 }
 
 // NewDataFlow 新建数据流
 func NewDataFlow[T any](bufSize uint) *DataFlow[T] {
+	return NewDataFlowWithConfig[T](&FlowConfig[T]{BufSize: bufSize})
+}
+
+// NewDataFlowWithConfig 根据配置新建数据流 (conf 为 nil 时使用默认配置; 不修改调用方配置)
+func NewDataFlowWithConfig[T any](conf *FlowConfig[T]) *DataFlow[T] {
+	res := &FlowConfig[T]{}
+	if conf != nil {
+		*res = *conf
+	}
+	conf = res
+	if conf.MetricsWindow <= 0 {
+		conf.MetricsWindow = 10 * time.Second
+	} else if conf.MetricsWindow < minMetricsWindow {
+		conf.MetricsWindow = minMetricsWindow
+	}
 	flow := &DataFlow[T]{
-		DataChannel:    make(chan T, bufSize),
-		ErrChannel:     make(chan error, bufSize/20+2),
-		DataDispatcher: NewDataDispatcher(),
+		ID:             fmt.Sprintf("flow-%d", atomic.AddInt64(&flowSeq, 1)),
+		DataChannel:    make(chan T, conf.BufSize),
+		ErrChannel:     make(chan error, conf.BufSize/20+2),
+		DataDispatcher: NewDataDispatcher[T](nil),
+		conf:           conf,
+		in:             make(chan T),
+		errIn:          make(chan error),
+		spill:          conf.SpillQueue,
+		metrics:        &flowMetrics{},
+		produced:       make(chan struct{}),
+		consumed:       make(chan struct{}),
+		closed:         make(chan struct{}),
+		pumped:         make(chan struct{}),
+		errPumped:      make(chan struct{}),
+	}
+	flow.ctx, flow.cancel = context.WithCancel(context.Background())
+	if conf.ManualStart {
+		return flow
 	}
 	return flow.Start()
 }
 
 // UseConsumeMode 使用消费模式
 func (f *DataFlow[T]) UseConsumeMode() *DataFlow[T] {
-	for !atomic.CompareAndSwapInt32(&f.FlowMode, f.FlowMode, DataConsume) {
-	}
+	atomic.StoreInt32(&f.FlowMode, DataConsume)
 	return f
 }
 
 // UseDispatchMode 使用分发模式
 func (f *DataFlow[T]) UseDispatchMode() *DataFlow[T] {
-	for !atomic.CompareAndSwapInt32(&f.FlowMode, f.FlowMode, DataDispatch) {
-	}
+	atomic.StoreInt32(&f.FlowMode, DataDispatch)
 	return f
 }
 
 // CustomDispatcher 自定义分发器
-func (f *DataFlow[T]) CustomDispatcher(dispatcher *DataDispatcher) *DataFlow[T] {
+func (f *DataFlow[T]) CustomDispatcher(dispatcher *DataDispatcher[T]) *DataFlow[T] {
 	f.DataDispatcher = dispatcher
 	return f
 }
@@ -148,70 +142,331 @@ func (f *DataFlow[T]) Status() int64 {
 	return atomic.LoadInt64(&f.State)
 }
 
-// Start 开启数据流
+// Context 运行上下文 (数据流关闭超时或外部上下文取消时取消, 消费方可据此提前退出)
+func (f *DataFlow[T]) Context() context.Context {
+	return f.ctx
+}
+
+// Done 数据流关闭信号 (关闭钩子执行完毕后关闭)
+func (f *DataFlow[T]) Done() <-chan struct{} {
+	return f.closed
+}
+
+// Start 开启数据流 (等价于 Run(context.Background()))
 func (f *DataFlow[T]) Start() *DataFlow[T] {
-	if atomic.LoadInt64(&f.State) == ClosedState {
-		// 启用管道, 允许注册生产方
-		for !atomic.CompareAndSwapInt64(&f.State, ClosedState, StartedState) {
-		}
+	return f.Run(context.Background())
+}
+
+// Run 开启数据流并绑定上下文: 上下文取消时取消生产方上下文, 并关闭数据流 (消费方继续消费剩余数据)
+// 数据流已开启时仅绑定上下文; 数据流不可重复开启
+func (f *DataFlow[T]) Run(ctx context.Context) *DataFlow[T] {
+	f.lock.Lock()
+	var hooks []func()
+	if !f.started {
+		f.started = true
+		atomic.StoreInt64(&f.State, StartedState)
+		f.metrics.sample(metricsSample{time: time.Now()})
+		go f.pump()
+		go f.errPump()
+		go f.watchMetrics()
+		hooks = f.onStart
+	}
+	f.lock.Unlock()
+	runHooks(hooks)
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				f.cancel()
+				f.beginStop()
+			case <-f.closed:
+			}
+		}()
 	}
 	return f
 }
 
-// Stop 关闭数据流
-func (f *DataFlow[T]) Stop() *DataFlow[T] {
-	// 关闭管道, 禁止注册生产方
-	for !atomic.CompareAndSwapInt64(&f.State, StartedState, ClosingState) {
+// Stop 关闭数据流: 禁止注册生产方, 等待生产方结束及消费方消费完剩余数据
+// ctx 到期时取消生产方上下文并返回 ctx.Err(), 关闭流程在后台继续 (生产方响应取消后完成)
+func (f *DataFlow[T]) Stop(ctx context.Context) error {
+	if !f.beginStop() {
+		return nil
+	}
+	select {
+	case <-f.closed:
+		return nil
+	case <-ctx.Done():
+		f.cancel()
+		return ctx.Err()
+	}
+}
+
+// beginStop 开始关闭 (幂等; 数据流未开启时返回 false)
+func (f *DataFlow[T]) beginStop() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if !f.started {
+		return false
+	}
+	if f.stopping {
+		return true
 	}
-	// 等待生产方结束生产
-	for atomic.LoadInt64(&f.Counter) > 0 {
-		runtime.Gosched()
+	f.stopping = true
+	atomic.StoreInt64(&f.State, ClosingState)
+	if f.Counter == 0 {
+		close(f.produced)
 	}
-	// 关闭数据和错误处理队列
+	go f.shutdown()
+	return true
+}
+
+// shutdown 关闭流程
+func (f *DataFlow[T]) shutdown() {
+	// 等待生产方结束生产及数据泵写完剩余数据后关闭数据管道
+	<-f.produced
+	close(f.in)
+	<-f.pumped
+	f.closeSpill()
+	f.flushErrors()
 	close(f.DataChannel)
-	close(f.ErrChannel)
-	// 等待消费方停止消费
-	for atomic.LoadInt64(&f.State) != ClosedState {
-		runtime.Gosched()
+	// 等待消费方消费完剩余数据 (消费方可继续写入错误管道)
+	f.lock.Lock()
+	f.inClosed = true
+	if f.Consumers == 0 {
+		close(f.consumed)
 	}
-	return f
+	f.lock.Unlock()
+	<-f.consumed
+	f.lock.Lock()
+	f.drained = true
+	hooks := f.onDrained
+	f.lock.Unlock()
+	runHooks(hooks)
+	// 关闭错误管道
+	close(f.errIn)
+	<-f.errPumped
+	close(f.ErrChannel)
+	f.lock.Lock()
+	atomic.StoreInt64(&f.State, ClosedState)
+	hooks = f.onClosed
+	f.lock.Unlock()
+	f.cancel()
+	runHooks(hooks)
+	close(f.closed)
 }
 
 // ProduceFn 生产方法
 type ProduceFn[T any] func(dc chan<- T, ec chan<- error, args ...any)
 
+// ProduceContextFn 上下文生产方法 (ctx 在数据流关闭超时或外部取消时取消; 返回的非取消错误写入错误管道)
+type ProduceContextFn[T any] func(ctx context.Context, dc chan<- T, ec chan<- error) error
+
 // Produce 注入生产方
 func (f *DataFlow[T]) Produce(fn ProduceFn[T], args ...any) *DataFlow[T] {
-	// 注册生产方
-	for atomic.LoadInt64(&f.State) == StartedState && !atomic.CompareAndSwapInt64(&f.Counter, f.Counter, f.Counter+1) {
-	}
-	// 数据流已关闭, 注册失败
-	if atomic.LoadInt64(&f.State) != StartedState {
-		return f
+	f.tryProduce(func(ctx context.Context, dc chan<- T, ec chan<- error) error {
+		fn(dc, ec, args...)
+		return nil
+	})
+	return f
+}
+
+// ProduceContext 注入上下文生产方
+func (f *DataFlow[T]) ProduceContext(fn ProduceContextFn[T]) *DataFlow[T] {
+	f.tryProduce(fn)
+	return f
+}
+
+// tryProduce 注入生产方 (数据流关闭中或已关闭时返回 false)
+func (f *DataFlow[T]) tryProduce(fn ProduceContextFn[T]) bool {
+	ctx, ok := f.addProducer()
+	if !ok {
+		return false
 	}
 	// 异步生产
 	go func() {
-		defer func() {
-			for !atomic.CompareAndSwapInt64(&f.Counter, f.Counter, f.Counter-1) {
-			}
-		}()
-		fn(f.DataChannel, f.ErrChannel, args)
+		defer f.doneProducer()
+		err := fn(ctx, f.in, f.errIn)
+		if err != nil && !(ctx.Err() != nil && errors.Is(err, ctx.Err())) {
+			f.errIn <- err
+		}
 	}()
+	return true
+}
+
+// addProducer 注册生产方 (数据流关闭中或已关闭时返回 false; 注册后需调用 doneProducer 注销)
+// 数据流开启前注册的生产方在数据泵启动后才能写入
+func (f *DataFlow[T]) addProducer() (context.Context, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.stopping {
+		return nil, false
+	}
+	atomic.AddInt64(&f.Counter, 1)
+	return f.ctx, true
+}
+
+// emit 以临时生产方身份写入一条数据 (数据流关闭中或已关闭时返回 ErrFlowClosed)
+func (f *DataFlow[T]) emit(ctx context.Context, v T) error {
+	fctx, ok := f.addProducer()
+	if !ok {
+		return ErrFlowClosed
+	}
+	defer f.doneProducer()
+	select {
+	case f.in <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-fctx.Done():
+		return fctx.Err()
+	}
+}
+
+// doneProducer 注销生产方
+func (f *DataFlow[T]) doneProducer() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if atomic.AddInt64(&f.Counter, -1) == 0 && f.stopping {
+		close(f.produced)
+	}
+}
+
+// Send 发送数据 (上下文取消时返回 ctx.Err(), 供生产方响应取消)
+func Send[T any](ctx context.Context, dc chan<- T, v T) error {
+	select {
+	case dc <- v:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnStart 注册开启钩子 (在 Start/Run 开启数据流时执行, 需配合 ManualStart 在开启前注册; 已开启时立即执行)
+func (f *DataFlow[T]) OnStart(fn func()) *DataFlow[T] {
+	f.lock.Lock()
+	started := f.started
+	if !started {
+		f.onStart = append(f.onStart, fn)
+	}
+	f.lock.Unlock()
+	if started {
+		fn()
+	}
+	return f
+}
+
+// OnDrained 注册排空钩子 (生产方全部结束且消费方消费完剩余数据时执行; 已排空时立即执行)
+func (f *DataFlow[T]) OnDrained(fn func()) *DataFlow[T] {
+	f.lock.Lock()
+	drained := f.drained
+	if !drained {
+		f.onDrained = append(f.onDrained, fn)
+	}
+	f.lock.Unlock()
+	if drained {
+		fn()
+	}
+	return f
+}
+
+// OnClosed 注册关闭钩子 (错误管道关闭后执行; 已关闭时立即执行)
+func (f *DataFlow[T]) OnClosed(fn func()) *DataFlow[T] {
+	f.lock.Lock()
+	closed := atomic.LoadInt64(&f.State) == ClosedState && f.stopping
+	if !closed {
+		f.onClosed = append(f.onClosed, fn)
+	}
+	f.lock.Unlock()
+	if closed {
+		fn()
+	}
 	return f
 }
 
+// runHooks 执行钩子
+func runHooks(hooks []func()) {
+	for _, hook := range hooks {
+		hook()
+	}
+}
+
 // ConsumeFn 消费方法
 type ConsumeFn[T any] func(dc <-chan T, ec chan<- error, args ...any)
 
-// Consume 注入消费方
+// Consume 注入消费方 (分发模式下数据由分发器消费, 忽略注入)
 func (f *DataFlow[T]) Consume(fn ConsumeFn[T], args ...any) *DataFlow[T] {
-	// 异步消费
+	if atomic.LoadInt32(&f.FlowMode) == DataDispatch {
+		return f
+	}
+	// 注册并异步消费
+	if !f.addConsumer() {
+		return f
+	}
 	go func() {
-		fn(f.DataChannel, f.ErrChannel, args)
-		defer func() {
-			for !atomic.CompareAndSwapInt64(&f.State, ClosingState, ClosedState) {
-			}
-		}()
+		defer f.doneConsumer()
+		// 消费方 panic 时上报错误并重启, 直到数据管道关闭
+		for !f.consumeSafely(fn, args...) {
+		}
+	}()
+	return f
+}
+
+// addConsumer 注册消费方 (数据已排空时返回 false)
+func (f *DataFlow[T]) addConsumer() bool {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.drained || (f.inClosed && f.Consumers == 0) {
+		return false
+	}
+	atomic.AddInt64(&f.Consumers, 1)
+	return true
+}
+
+// doneConsumer 注销消费方
+func (f *DataFlow[T]) doneConsumer() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if atomic.AddInt64(&f.Consumers, -1) == 0 && f.inClosed {
+		close(f.consumed)
+	}
+}
+
+// ConsumeParallel 注入 n 个并行消费方 (共享数据管道, 全部结束后数据流才会关闭)
+func (f *DataFlow[T]) ConsumeParallel(n int, fn ConsumeFn[T], args ...any) *DataFlow[T] {
+	for i := 0; i < n; i++ {
+		f.Consume(fn, args...)
+	}
+	return f
+}
+
+// consumeSafely 执行消费方法 (panic 时转换为 async.PanicError 写入错误管道并返回 false)
+func (f *DataFlow[T]) consumeSafely(fn ConsumeFn[T], args ...any) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			f.errIn <- &async.PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	fn(f.DataChannel, f.errIn, args...)
+	return true
+}
+
+// Dispatch 切换为分发模式并启动分发 (数据经 DataDispatcher 分发到输出流, 数据流关闭后释放分发器)
+func (f *DataFlow[T]) Dispatch() *DataFlow[T] {
+	f.UseDispatchMode()
+	dispatcher := f.DataDispatcher
+	dispatcher.bind()
+	// 异步分发 (分发器作为消费方注册)
+	if !f.addConsumer() {
+		dispatcher.unbind()
+		return f
+	}
+	go func() {
+		defer f.doneConsumer()
+		defer dispatcher.unbind()
+		for v := range f.DataChannel {
+			// 分发失败的数据已由 OnDrop 回调处理
+			_ = dispatcher.Dispatch(v)
+		}
 	}()
 	return f
 }
@@ -223,7 +478,7 @@ type ErrorHandleFn[T any] func(ec <-chan error, args ...any)
 func (f *DataFlow[T]) OnError(fn ErrorHandleFn[T], args ...any) *DataFlow[T] {
 	// 异步错误处理
 	go func() {
-		fn(f.ErrChannel, args)
+		fn(f.ErrChannel, args...)
 	}()
 	return f
 }
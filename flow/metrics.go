@@ -0,0 +1,199 @@
+package flow
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	metricsSlots     = 10                    // 吞吐量滑动窗口采样槽数
+	minMetricsWindow = 10 * time.Millisecond // 最小吞吐量滑动窗口
+)
+
+// FlowMetrics 数据流指标快照
+// 生产方写入速率 (InRate) 持续高于消费吞吐量 (Throughput) 且队列深度接近容量、阻塞时间增长时, 管道受限于消费方;
+// 队列长期为空时受限于生产方
+type FlowMetrics struct {
+	ID          string        `json:"id"`           // 数据流 ID
+	State       int64         `json:"state"`        // 数据流状态
+	ItemsIn     int64         `json:"items_in"`     // 生产方写入数据数
+	ItemsOut    int64         `json:"items_out"`    // 消费方读取数据数
+	Dropped     int64         `json:"dropped"`      // 丢弃数据数
+	Spilled     int64         `json:"spilled"`      // 溢出到磁盘的数据数 (累计)
+	SpillDepth  int64         `json:"spill_depth"`  // 溢出队列当前长度
+	QueueDepth  int           `json:"queue_depth"`  // 数据管道当前长度
+	QueueCap    int           `json:"queue_cap"`    // 数据管道容量
+	Producers   int64         `json:"producers"`    // 生产方数
+	Consumers   int64         `json:"consumers"`    // 消费方数
+	BlockedTime time.Duration `json:"blocked_time"` // 生产方因缓冲已满阻塞的累计时间
+	Errors      int64         `json:"errors"`       // 错误数
+	InRate      float64       `json:"in_rate"`      // 滑动窗口内写入速率 (条/秒)
+	Throughput  float64       `json:"throughput"`   // 滑动窗口内消费吞吐量 (条/秒)
+	Window      time.Duration `json:"window"`       // 滑动窗口大小
+	Time        time.Time     `json:"time"`         // 快照时间
+}
+
+// JSON 导出 JSON
+func (m FlowMetrics) JSON() ([]byte, error) {
+	return json.Marshal(m)
+}
+
+// String 格式化输出
+func (m FlowMetrics) String() string {
+	return fmt.Sprintf("flow %s: in=%d out=%d dropped=%d spilled=%d queue=%d/%d spill=%d blocked=%s errors=%d in_rate=%.2f/s throughput=%.2f/s",
+		m.ID, m.ItemsIn, m.ItemsOut, m.Dropped, m.Spilled, m.QueueDepth, m.QueueCap, m.SpillDepth,
+		m.BlockedTime, m.Errors, m.InRate, m.Throughput)
+}
+
+// metricsSample 吞吐量采样
+type metricsSample struct {
+	time time.Time // 采样时间
+	in   int64     // 写入数据数
+	out  int64     // 读取数据数
+}
+
+// flowMetrics 数据流指标计数器
+type flowMetrics struct {
+	in        int64 // 写入数据数
+	delivered int64 // 写入数据管道的数据数
+	evicted   int64 // 从数据管道中淘汰的数据数 (OverflowDropOldest)
+	drops     int64 // 丢弃数据数
+	spills    int64 // 溢出数据数
+	blockedNs int64 // 阻塞时间 (纳秒)
+	errors    int64 // 错误数
+
+	lock    sync.Mutex      // 采样锁
+	samples []metricsSample // 采样环
+	next    int             // 下一个采样槽
+}
+
+// received 记录写入
+func (m *flowMetrics) received() {
+	atomic.AddInt64(&m.in, 1)
+}
+
+// deliver 记录写入数据管道
+func (m *flowMetrics) deliver() {
+	atomic.AddInt64(&m.delivered, 1)
+}
+
+// evict 记录淘汰
+func (m *flowMetrics) evict() {
+	atomic.AddInt64(&m.evicted, 1)
+}
+
+// dropped 记录丢弃
+func (m *flowMetrics) dropped() {
+	atomic.AddInt64(&m.drops, 1)
+}
+
+// spilled 记录溢出
+func (m *flowMetrics) spilled() {
+	atomic.AddInt64(&m.spills, 1)
+}
+
+// blocked 记录阻塞时间
+func (m *flowMetrics) blocked(d time.Duration) {
+	atomic.AddInt64(&m.blockedNs, int64(d))
+}
+
+// failed 记录错误
+func (m *flowMetrics) failed() {
+	atomic.AddInt64(&m.errors, 1)
+}
+
+// sample 采样
+func (m *flowMetrics) sample(s metricsSample) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if len(m.samples) < metricsSlots+1 {
+		m.samples = append(m.samples, s)
+		return
+	}
+	m.samples[m.next] = s
+	m.next = (m.next + 1) % len(m.samples)
+}
+
+// oldest 窗口内最早的采样
+func (m *flowMetrics) oldest() (metricsSample, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if len(m.samples) == 0 {
+		return metricsSample{}, false
+	}
+	if len(m.samples) < metricsSlots+1 {
+		return m.samples[0], true
+	}
+	return m.samples[m.next], true
+}
+
+// Metrics 获取数据流指标快照
+func (f *DataFlow[T]) Metrics() FlowMetrics {
+	m := f.metrics
+	now := time.Now()
+	depth := len(f.DataChannel)
+	res := FlowMetrics{
+		ID:          f.ID,
+		State:       f.Status(),
+		ItemsIn:     atomic.LoadInt64(&m.in),
+		Dropped:     atomic.LoadInt64(&m.drops),
+		Spilled:     atomic.LoadInt64(&m.spills),
+		QueueDepth:  depth,
+		QueueCap:    cap(f.DataChannel),
+		Producers:   atomic.LoadInt64(&f.Counter),
+		Consumers:   atomic.LoadInt64(&f.Consumers),
+		BlockedTime: time.Duration(atomic.LoadInt64(&m.blockedNs)),
+		Errors:      atomic.LoadInt64(&m.errors),
+		Window:      f.conf.MetricsWindow,
+		Time:        now,
+	}
+	res.ItemsOut = m.out(depth)
+	if spill := f.spillQueue(); spill != nil {
+		res.SpillDepth = spill.Len()
+	}
+	if s, ok := m.oldest(); ok {
+		if elapsed := now.Sub(s.time).Seconds(); elapsed > 0 {
+			res.InRate = float64(res.ItemsIn-s.in) / elapsed
+			res.Throughput = float64(res.ItemsOut-s.out) / elapsed
+		}
+	}
+	return res
+}
+
+// out 消费方读取数据数 (写入数据管道的数据扣除被淘汰及仍在管道中的数据)
+func (m *flowMetrics) out(depth int) int64 {
+	out := atomic.LoadInt64(&m.delivered) - atomic.LoadInt64(&m.evicted) - int64(depth)
+	if out < 0 {
+		return 0
+	}
+	return out
+}
+
+// watchMetrics 定时采样及导出指标 (数据流关闭后导出最终指标)
+func (f *DataFlow[T]) watchMetrics() {
+	m, conf := f.metrics, f.conf
+	sampleTicker := time.NewTicker(conf.MetricsWindow / metricsSlots)
+	defer sampleTicker.Stop()
+	var export <-chan time.Time
+	if conf.MetricsInterval > 0 && conf.OnMetrics != nil {
+		exportTicker := time.NewTicker(conf.MetricsInterval)
+		defer exportTicker.Stop()
+		export = exportTicker.C
+	}
+	for {
+		select {
+		case now := <-sampleTicker.C:
+			m.sample(metricsSample{time: now, in: atomic.LoadInt64(&m.in), out: m.out(len(f.DataChannel))})
+		case <-export:
+			conf.OnMetrics(f.Metrics())
+		case <-f.closed:
+			if conf.OnMetrics != nil {
+				conf.OnMetrics(f.Metrics())
+			}
+			return
+		}
+	}
+}
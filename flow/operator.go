@@ -0,0 +1,348 @@
+package flow
+
+import (
+	"context"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// 流式算子
+// 算子以输入流的消费方身份读取数据, 将结果写入新建的输出流 (缓冲大小与输入流一致):
+// 1. 输入流的错误管道由算子接管, 错误与算子产生的错误一并转发到输出流的错误管道;
+// 2. 输入流关闭 (Stop) 后算子处理完剩余数据并关闭输出流, 因此只需关闭管道首端的数据流, Stop 返回时整条管道已处理完毕;
+// 3. 输出流需注入消费方 (或继续连接算子), 否则输入流关闭时阻塞;
+// 4. 算子注册为输出流的生产方, 直接关闭输出流时等待输入流关闭、算子结束写入.
+
+// Pair 数据对 (Zip 输出)
+type Pair[A, B any] struct {
+	First  A // 第一个输入流数据
+	Second B // 第二个输入流数据
+}
+
+// Map 转换数据 (返回错误时丢弃该数据并转发错误)
+func Map[A, B any](src *DataFlow[A], fn func(A) (B, error)) *DataFlow[B] {
+	dst := newOutput[B](src)
+	connect(src, dst, nil, func(dc <-chan A, out chan<- B, ec chan<- error, _ func()) {
+		for v := range dc {
+			res, err := fn(v)
+			if err != nil {
+				ec <- err
+				continue
+			}
+			out <- res
+		}
+	})
+	return dst
+}
+
+// Filter 过滤数据 (保留 fn 返回 true 的数据)
+func Filter[T any](src *DataFlow[T], fn func(T) bool) *DataFlow[T] {
+	dst := newOutput[T](src)
+	connect(src, dst, nil, func(dc <-chan T, out chan<- T, ec chan<- error, _ func()) {
+		for v := range dc {
+			if fn(v) {
+				out <- v
+			}
+		}
+	})
+	return dst
+}
+
+// FlatMap 将每条数据展开为多条 (返回错误时丢弃该数据并转发错误)
+func FlatMap[A, B any](src *DataFlow[A], fn func(A) ([]B, error)) *DataFlow[B] {
+	dst := newOutput[B](src)
+	connect(src, dst, nil, func(dc <-chan A, out chan<- B, ec chan<- error, _ func()) {
+		for v := range dc {
+			res, err := fn(v)
+			if err != nil {
+				ec <- err
+				continue
+			}
+			for _, item := range res {
+				out <- item
+			}
+		}
+	})
+	return dst
+}
+
+// Batch 按数量或时间分批 (攒满 size 条或首条数据等待超过 maxWait 时输出; maxWait <= 0 时只按数量分批, 关闭时输出剩余数据; size 需大于 0)
+func Batch[T any](src *DataFlow[T], size int, maxWait time.Duration) *DataFlow[[]T] {
+	if size <= 0 {
+		panic("flow: Batch size must be positive")
+	}
+	dst := newOutput[[]T](src)
+	connect(src, dst, nil, func(dc <-chan T, out chan<- []T, ec chan<- error, _ func()) {
+		var batch []T
+		var timer *time.Timer
+		var expire <-chan time.Time
+		flush := func() {
+			if timer != nil {
+				timer.Stop()
+				timer, expire = nil, nil
+			}
+			if len(batch) > 0 {
+				out <- batch
+				batch = nil
+			}
+		}
+		for {
+			select {
+			case v, ok := <-dc:
+				if !ok {
+					flush()
+					return
+				}
+				batch = append(batch, v)
+				if len(batch) >= size {
+					flush()
+				} else if len(batch) == 1 && maxWait > 0 {
+					timer = time.NewTimer(maxWait)
+					expire = timer.C
+				}
+			case <-expire:
+				timer, expire = nil, nil
+				flush()
+			}
+		}
+	})
+	return dst
+}
+
+// Window 滑动窗口 (窗口大小 size, 每 step 条数据输出一次最近 size 条; 只输出满窗口; size 及 step 需大于 0)
+func Window[T any](src *DataFlow[T], size, step int) *DataFlow[[]T] {
+	if size <= 0 || step <= 0 {
+		panic("flow: Window size and step must be positive")
+	}
+	dst := newOutput[[]T](src)
+	connect(src, dst, nil, func(dc <-chan T, out chan<- []T, ec chan<- error, _ func()) {
+		window := make([]T, 0, size)
+		count := 0
+		for v := range dc {
+			if len(window) == size {
+				window = window[1:]
+			}
+			window = append(window, v)
+			count++
+			if len(window) == size && (count-size)%step == 0 {
+				out <- append([]T(nil), window...)
+			}
+		}
+	})
+	return dst
+}
+
+// Distinct 去重 (按 keyFn 返回的 key 保留首次出现的数据; 已出现的 key 常驻内存)
+func Distinct[T any, K comparable](src *DataFlow[T], keyFn func(T) K) *DataFlow[T] {
+	dst := newOutput[T](src)
+	connect(src, dst, nil, func(dc <-chan T, out chan<- T, ec chan<- error, _ func()) {
+		seen := make(map[K]struct{})
+		for v := range dc {
+			key := keyFn(v)
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			out <- v
+		}
+	})
+	return dst
+}
+
+// Take 取前 n 条数据 (取满后立即关闭输出流, 输入流剩余数据及错误被丢弃)
+func Take[T any](src *DataFlow[T], n int) *DataFlow[T] {
+	dst := newOutput[T](src)
+	connect(src, dst, nil, func(dc <-chan T, out chan<- T, ec chan<- error, closeOut func()) {
+		taken := 0
+		if n <= 0 {
+			closeOut()
+		}
+		for v := range dc {
+			if taken >= n {
+				continue
+			}
+			out <- v
+			if taken++; taken == n {
+				closeOut()
+			}
+		}
+	})
+	return dst
+}
+
+// Reduce 聚合数据 (输入流关闭时输出最终结果)
+func Reduce[T, R any](src *DataFlow[T], init R, fn func(R, T) R) *DataFlow[R] {
+	dst := NewDataFlow[R](1)
+	connect(src, dst, nil, func(dc <-chan T, out chan<- R, ec chan<- error, _ func()) {
+		acc := init
+		for v := range dc {
+			acc = fn(acc, v)
+		}
+		out <- acc
+	})
+	return dst
+}
+
+// Merge 合并多个输入流 (全部输入流关闭后关闭输出流)
+func Merge[T any](srcs ...*DataFlow[T]) *DataFlow[T] {
+	size := 0
+	for _, src := range srcs {
+		size += cap(src.DataChannel)
+	}
+	dst := NewDataFlow[T](uint(size))
+	if len(srcs) == 0 {
+		go dst.Stop(context.Background())
+		return dst
+	}
+	release := releaseAfter(dst, len(srcs))
+	for _, src := range srcs {
+		connect(src, dst, release, func(dc <-chan T, out chan<- T, ec chan<- error, _ func()) {
+			for v := range dc {
+				out <- v
+			}
+		})
+	}
+	return dst
+}
+
+// Zip 按顺序配对两个输入流的数据 (任一输入流结束后停止配对, 另一输入流剩余数据被丢弃)
+func Zip[A, B any](a *DataFlow[A], b *DataFlow[B]) *DataFlow[Pair[A, B]] {
+	dst := newOutput[Pair[A, B]](a)
+	release := releaseAfter(dst, 3)
+	// 两个输入流的消费方各自转发数据, 由配对协程读取, 输入流可按任意顺序关闭
+	chA, chB := make(chan A), make(chan B)
+	dst.addProducer()
+	connect(a, dst, release, func(dc <-chan A, out chan<- Pair[A, B], ec chan<- error, _ func()) {
+		defer close(chA)
+		for v := range dc {
+			chA <- v
+		}
+	})
+	connect(b, dst, release, func(dc <-chan B, out chan<- Pair[A, B], ec chan<- error, _ func()) {
+		defer close(chB)
+		for v := range dc {
+			chB <- v
+		}
+	})
+	go func() {
+		defer release()
+		defer dst.doneProducer()
+		for va := range chA {
+			vb, ok := <-chB
+			if !ok {
+				break
+			}
+			dst.in <- Pair[A, B]{First: va, Second: vb}
+		}
+		// 排空剩余数据, 保证输入流可正常关闭
+		go func() {
+			for range chA {
+			}
+		}()
+		for range chB {
+		}
+	}()
+	return dst
+}
+
+// newOutput 新建与输入流缓冲大小一致的输出流
+func newOutput[B, A any](src *DataFlow[A]) *DataFlow[B] {
+	return NewDataFlow[B](uint(cap(src.DataChannel)))
+}
+
+// operatorFn 算子处理方法 (closeOut 用于提前关闭输出流, 关闭后不可再写入 out/ec)
+type operatorFn[A, B any] func(dc <-chan A, out chan<- B, ec chan<- error, closeOut func())
+
+// connect 连接输入流与输出流
+// release 为空时算子结束后关闭输出流, 否则算子结束后调用 release (多输入算子由最后一个结束者关闭输出流)
+func connect[A, B any](src *DataFlow[A], dst *DataFlow[B], release func(), fn operatorFn[A, B]) {
+	// 注册为输出流的生产方, 输出流关闭时等待算子结束写入
+	dst.addProducer()
+	src.Consume(func(dc <-chan A, _ chan<- error, _ ...any) {
+		// 转发输入流错误 (输入流数据处理完毕后转发剩余错误; 提前关闭后丢弃)
+		detach, finish := make(chan struct{}), make(chan struct{})
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			for {
+				select {
+				case err, ok := <-src.ErrChannel:
+					if !ok {
+						return
+					}
+					select {
+					case dst.errIn <- err:
+						continue
+					case <-detach:
+					}
+				case <-finish:
+					// 输入流生产方均已结束, 错误管道中只剩缓冲的错误
+					for {
+						select {
+						case err, ok := <-src.ErrChannel:
+							if !ok {
+								return
+							}
+							dst.errIn <- err
+						default:
+							return
+						}
+					}
+				case <-detach:
+				}
+				go drainErrors(src.ErrChannel)
+				return
+			}
+		}()
+		once := sync.Once{}
+		closeOut := func() {
+			once.Do(func() {
+				close(detach)
+				<-forwarded
+				dst.doneProducer()
+				if release != nil {
+					release()
+				} else {
+					_ = dst.Stop(context.Background())
+				}
+			})
+		}
+		// 算子 panic 时上报错误并重启, 直到输入流数据管道关闭
+		for !runOperator(dc, dst.in, dst.errIn, closeOut, fn) {
+		}
+		close(finish)
+		<-forwarded
+		closeOut()
+	})
+}
+
+// runOperator 执行算子 (panic 时转换为 async.PanicError 写入错误管道并返回 false)
+func runOperator[A, B any](dc <-chan A, out chan<- B, ec chan<- error, closeOut func(), fn operatorFn[A, B]) (ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec <- &async.PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	fn(dc, out, ec, closeOut)
+	return true
+}
+
+// releaseAfter 第 n 次调用时关闭输出流
+func releaseAfter[T any](dst *DataFlow[T], n int) func() {
+	remaining := int64(n)
+	return func() {
+		if atomic.AddInt64(&remaining, -1) == 0 {
+			_ = dst.Stop(context.Background())
+		}
+	}
+}
+
+// drainErrors 丢弃错误直到管道关闭
+func drainErrors(ec <-chan error) {
+	for range ec {
+	}
+}
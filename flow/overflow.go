@@ -0,0 +1,385 @@
+package flow
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 溢出策略常量枚举 (数据管道缓冲已满时的处理方式)
+const (
+	OverflowBlock      = iota // 阻塞生产方 (默认)
+	OverflowDropNewest        // 丢弃新数据
+	OverflowDropOldest        // 丢弃缓冲中最旧的数据 (无缓冲数据流退化为阻塞)
+	OverflowSpill             // 溢出到磁盘 (缓冲空闲后按顺序回填)
+)
+
+var ErrSpillClosed = errors.New("flow: spill queue is closed") // 溢出队列已关闭
+
+// Codec 数据编解码器
+type Codec[T any] interface {
+	Encode(item T) ([]byte, error)          // 编码
+	Decode(data []byte) (item T, err error) // 解码
+}
+
+// JSONCodec JSON 编解码器
+type JSONCodec[T any] struct{}
+
+// Encode 编码
+func (JSONCodec[T]) Encode(item T) ([]byte, error) {
+	return json.Marshal(item)
+}
+
+// Decode 解码
+func (JSONCodec[T]) Decode(data []byte) (item T, err error) {
+	err = json.Unmarshal(data, &item)
+	return
+}
+
+// SpillQueue 溢出队列 (OverflowSpill 策略的磁盘缓冲, 需按写入顺序读出)
+type SpillQueue[T any] interface {
+	Push(item T) error                 // 写入
+	Pop() (item T, ok bool, err error) // 读出 (队列为空时 ok 为 false)
+	Len() int64                        // 队列长度
+	Close() error                      // 关闭并释放资源
+}
+
+// FileSpill 单文件溢出队列 (长度前缀记录, 读空后截断文件复用空间; 不保证崩溃后可恢复)
+type FileSpill[T any] struct {
+	lock   sync.Mutex    // 队列锁
+	codec  Codec[T]      // 编解码器
+	file   *os.File      // 溢出文件
+	writer *bufio.Writer // 写缓冲
+	rpos   int64         // 读偏移
+	wpos   int64         // 写偏移
+	count  int64         // 队列长度
+	closed bool          // 是否已关闭
+}
+
+// NewFileSpill 新建单文件溢出队列 (dir 为空时使用系统临时目录, codec 为 nil 时使用 JSONCodec)
+func NewFileSpill[T any](dir string, codec Codec[T]) (*FileSpill[T], error) {
+	if codec == nil {
+		codec = JSONCodec[T]{}
+	}
+	file, err := os.CreateTemp(dir, "flow-spill-*.dat")
+	if err != nil {
+		return nil, err
+	}
+	return &FileSpill[T]{codec: codec, file: file, writer: bufio.NewWriter(file)}, nil
+}
+
+// Push 写入
+func (s *FileSpill[T]) Push(item T) error {
+	data, err := s.codec.Encode(item)
+	if err != nil {
+		return err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return ErrSpillClosed
+	}
+	var head [4]byte
+	binary.BigEndian.PutUint32(head[:], uint32(len(data)))
+	if _, err = s.writer.Write(head[:]); err != nil {
+		return err
+	}
+	if _, err = s.writer.Write(data); err != nil {
+		return err
+	}
+	s.wpos += int64(len(head) + len(data))
+	s.count++
+	return nil
+}
+
+// Pop 读出
+func (s *FileSpill[T]) Pop() (item T, ok bool, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return item, false, ErrSpillClosed
+	}
+	if s.count == 0 {
+		return item, false, nil
+	}
+	if err = s.writer.Flush(); err != nil {
+		return item, false, err
+	}
+	var head [4]byte
+	if _, err = s.file.ReadAt(head[:], s.rpos); err != nil {
+		return item, false, err
+	}
+	data := make([]byte, binary.BigEndian.Uint32(head[:]))
+	if _, err = s.file.ReadAt(data, s.rpos+int64(len(head))); err != nil && err != io.EOF {
+		return item, false, err
+	}
+	s.rpos += int64(len(head) + len(data))
+	s.count--
+	// 读空后截断文件
+	if s.count == 0 {
+		if err = s.file.Truncate(0); err != nil {
+			return item, false, err
+		}
+		if _, err = s.file.Seek(0, io.SeekStart); err != nil {
+			return item, false, err
+		}
+		s.writer.Reset(s.file)
+		s.rpos, s.wpos = 0, 0
+	}
+	item, err = s.codec.Decode(data)
+	return item, err == nil, err
+}
+
+// Len 队列长度
+func (s *FileSpill[T]) Len() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.count
+}
+
+// Close 关闭并删除溢出文件
+func (s *FileSpill[T]) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	_ = s.file.Close()
+	return os.Remove(s.file.Name())
+}
+
+// ================================ 数据泵 ================================= //
+
+// pump 将输入管道的数据按溢出策略写入数据管道 (输入管道关闭且溢出数据回填完毕后结束)
+// 已从生产方接收的数据只在丢弃策略下丢弃, 运行上下文取消后仍写入数据管道 (消费方继续排空)
+func (f *DataFlow[T]) pump() {
+	defer close(f.pumped)
+	var head *T // 等待回填的溢出数据
+	for {
+		// 存在溢出数据时新数据继续溢出, 保证写入顺序
+		if head == nil {
+			head = f.popSpill()
+		}
+		if head != nil {
+			select {
+			case f.DataChannel <- *head:
+				f.metrics.deliver()
+				head = nil
+			case v, ok := <-f.in:
+				if !ok {
+					f.flushSpill(head)
+					return
+				}
+				f.metrics.received()
+				f.spillItem(v)
+			case <-f.ctx.Done():
+				f.deliver(*head)
+				head = nil
+			}
+			continue
+		}
+		v, ok := <-f.in
+		if !ok {
+			return
+		}
+		f.metrics.received()
+		f.push(v)
+	}
+}
+
+// errPump 将错误输入管道的错误写入错误管道并计数
+func (f *DataFlow[T]) errPump() {
+	defer close(f.errPumped)
+	for err := range f.errIn {
+		if barrier, ok := err.(errBarrier); ok {
+			close(barrier)
+			continue
+		}
+		f.metrics.failed()
+		f.ErrChannel <- err
+	}
+}
+
+// errBarrier 错误泵屏障 (错误泵处理到屏障时, 之前接收的错误均已写入错误管道)
+type errBarrier chan struct{}
+
+// Error 实现 error 接口
+func (errBarrier) Error() string {
+	return "flow: error barrier"
+}
+
+// flushErrors 等待已接收的错误写入错误管道 (关闭数据管道前调用, 保证消费方读完数据后可读到生产方的全部错误)
+func (f *DataFlow[T]) flushErrors() {
+	barrier := make(errBarrier)
+	f.errIn <- barrier
+	<-barrier
+}
+
+// push 按溢出策略写入数据管道
+func (f *DataFlow[T]) push(v T) {
+	// 缓冲未满时直接写入
+	select {
+	case f.DataChannel <- v:
+		f.metrics.deliver()
+		return
+	default:
+	}
+	switch f.conf.Overflow {
+	case OverflowDropNewest:
+		f.drop(v)
+	case OverflowDropOldest:
+		if cap(f.DataChannel) == 0 {
+			f.pushBlocking(v)
+			return
+		}
+		for {
+			select {
+			case f.DataChannel <- v:
+				f.metrics.deliver()
+				return
+			default:
+			}
+			select {
+			case old := <-f.DataChannel:
+				f.metrics.evict()
+				f.drop(old)
+			default:
+			}
+		}
+	case OverflowSpill:
+		f.spillItem(v)
+	default:
+		f.pushBlocking(v)
+	}
+}
+
+// pushBlocking 阻塞写入数据管道并记录阻塞时间
+func (f *DataFlow[T]) pushBlocking(v T) {
+	start := time.Now()
+	f.deliver(v)
+	f.metrics.blocked(time.Since(start))
+}
+
+// deliver 阻塞写入数据管道
+// 运行上下文取消后没有消费方时丢弃 (与数据流关闭时缓冲中无人消费的数据一致), 否则等待消费方排空
+func (f *DataFlow[T]) deliver(v T) {
+	select {
+	case f.DataChannel <- v:
+		f.metrics.deliver()
+		return
+	case <-f.ctx.Done():
+	}
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case f.DataChannel <- v:
+			f.metrics.deliver()
+			return
+		case <-timer.C:
+			if atomic.LoadInt64(&f.Consumers) == 0 {
+				f.drop(v)
+				return
+			}
+			timer.Reset(10 * time.Millisecond)
+		}
+	}
+}
+
+// spillItem 溢出数据 (首次溢出时创建溢出队列; 溢出失败时丢弃数据并上报错误)
+func (f *DataFlow[T]) spillItem(v T) {
+	spill, err := f.openSpill()
+	if err == nil {
+		err = spill.Push(v)
+	}
+	if err != nil {
+		f.drop(v)
+		f.reportError(err)
+		return
+	}
+	f.metrics.spilled()
+}
+
+// popSpill 读出溢出数据 (无溢出数据时返回 nil; 读取失败时上报错误, 剩余溢出数据计为丢弃)
+func (f *DataFlow[T]) popSpill() *T {
+	spill := f.spillQueue()
+	if spill == nil || spill.Len() == 0 {
+		return nil
+	}
+	v, ok, err := spill.Pop()
+	if err != nil {
+		f.reportError(err)
+		f.discardSpill()
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	return &v
+}
+
+// flushSpill 输入管道关闭后按顺序回填全部溢出数据
+func (f *DataFlow[T]) flushSpill(head *T) {
+	for ; head != nil; head = f.popSpill() {
+		f.deliver(*head)
+	}
+}
+
+// spillQueue 当前溢出队列
+func (f *DataFlow[T]) spillQueue() SpillQueue[T] {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.spill
+}
+
+// openSpill 获取溢出队列 (未配置时在 SpillDir 下新建 FileSpill)
+func (f *DataFlow[T]) openSpill() (SpillQueue[T], error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.spill != nil {
+		return f.spill, nil
+	}
+	spill, err := NewFileSpill[T](f.conf.SpillDir, f.conf.Codec)
+	if err != nil {
+		return nil, err
+	}
+	f.spill = spill
+	return spill, nil
+}
+
+// discardSpill 丢弃溢出队列 (无法读取的剩余数据计为丢弃)
+func (f *DataFlow[T]) discardSpill() {
+	f.lock.Lock()
+	spill := f.spill
+	f.spill = nil
+	f.lock.Unlock()
+	atomic.AddInt64(&f.metrics.drops, spill.Len())
+	_ = spill.Close()
+}
+
+// closeSpill 关闭溢出队列
+func (f *DataFlow[T]) closeSpill() {
+	if spill := f.spillQueue(); spill != nil {
+		_ = spill.Close()
+	}
+}
+
+// drop 丢弃数据
+func (f *DataFlow[T]) drop(v T) {
+	f.metrics.dropped()
+	if f.conf.OnDrop != nil {
+		f.conf.OnDrop(v)
+	}
+}
+
+// reportError 上报数据流内部错误
+func (f *DataFlow[T]) reportError(err error) {
+	f.errIn <- err
+}
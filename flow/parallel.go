@@ -0,0 +1,168 @@
+package flow
+
+import (
+	"hash/fnv"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// 并行处理模式常量枚举
+const (
+	ParallelUnordered = iota // 无序 (处理完成即输出, 吞吐量最高)
+	ParallelOrdered          // 有序 (按输入顺序输出)
+	ParallelKeyed            // 按 key 分组 (相同 key 由同一协程按输入顺序处理)
+)
+
+// ParallelConfig 并行处理配置
+type ParallelConfig[T any] struct {
+	Workers int                 // 并行协程数 (默认 runtime.NumCPU())
+	Mode    int                 // 并行处理模式 [ParallelUnordered:无序, ParallelOrdered:有序, ParallelKeyed:按 key 分组; 默认无序]
+	KeyFn   func(item T) string // 数据 key (按 key 分组模式必填, 未配置时 ParallelMap / ConsumeEach panic)
+}
+
+// parallelResult 并行处理结果
+type parallelResult[B any] struct {
+	value B     // 处理结果
+	err   error // 处理错误
+}
+
+// ParallelMap 并行转换数据 (返回错误或 panic 时丢弃该数据并写入输出流错误管道, 处理协程继续运行)
+func ParallelMap[A, B any](src *DataFlow[A], fn func(A) (B, error), conf *ParallelConfig[A]) *DataFlow[B] {
+	conf = parallelConfig(conf)
+	dst := newOutput[B](src)
+	connect(src, dst, nil, func(dc <-chan A, out chan<- B, ec chan<- error, _ func()) {
+		emit := func(res parallelResult[B]) {
+			if res.err != nil {
+				ec <- res.err
+			} else {
+				out <- res.value
+			}
+		}
+		switch conf.Mode {
+		case ParallelOrdered:
+			parallelOrdered(dc, conf.Workers, fn, emit)
+		case ParallelKeyed:
+			parallelKeyed(dc, conf.Workers, conf.KeyFn, fn, emit)
+		default:
+			parallelUnordered(dc, conf.Workers, fn, emit)
+		}
+	})
+	return dst
+}
+
+// ConsumeEach 并行逐条消费 (支持无序及按 key 分组模式; 返回错误或 panic 时写入错误管道, 处理协程继续运行)
+func (f *DataFlow[T]) ConsumeEach(fn func(item T) error, conf *ParallelConfig[T]) *DataFlow[T] {
+	conf = parallelConfig(conf)
+	handle := func(item T) (struct{}, error) {
+		return struct{}{}, fn(item)
+	}
+	return f.Consume(func(dc <-chan T, ec chan<- error, args ...any) {
+		emit := func(res parallelResult[struct{}]) {
+			if res.err != nil {
+				ec <- res.err
+			}
+		}
+		if conf.Mode == ParallelKeyed {
+			parallelKeyed(dc, conf.Workers, conf.KeyFn, handle, emit)
+		} else {
+			parallelUnordered(dc, conf.Workers, handle, emit)
+		}
+	})
+}
+
+// parallelConfig 复制配置并补全默认值 (按 key 分组模式未配置 KeyFn 时 panic)
+func parallelConfig[T any](conf *ParallelConfig[T]) *ParallelConfig[T] {
+	res := &ParallelConfig[T]{}
+	if conf != nil {
+		*res = *conf
+	}
+	if res.Workers <= 0 {
+		res.Workers = runtime.NumCPU()
+	}
+	if res.Mode == ParallelKeyed && res.KeyFn == nil {
+		panic("flow: ParallelKeyed mode requires a KeyFn")
+	}
+	return res
+}
+
+// parallelUnordered 无序并行处理
+func parallelUnordered[A, B any](dc <-chan A, workers int, fn func(A) (B, error), emit func(parallelResult[B])) {
+	var lock sync.Mutex
+	group := sync.WaitGroup{}
+	group.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer group.Done()
+			for v := range dc {
+				res := callSafely(fn, v)
+				lock.Lock()
+				emit(res)
+				lock.Unlock()
+			}
+		}()
+	}
+	group.Wait()
+}
+
+// parallelOrdered 有序并行处理 (按输入顺序登记结果管道, 依次等待输出, 至多 workers 条数据处理中)
+func parallelOrdered[A, B any](dc <-chan A, workers int, fn func(A) (B, error), emit func(parallelResult[B])) {
+	pending := make(chan chan parallelResult[B], workers-1)
+	go func() {
+		defer close(pending)
+		for v := range dc {
+			ch := make(chan parallelResult[B], 1)
+			pending <- ch
+			go func(v A) {
+				ch <- callSafely(fn, v)
+			}(v)
+		}
+	}()
+	for ch := range pending {
+		emit(<-ch)
+	}
+}
+
+// parallelKeyed 按 key 分组并行处理 (相同 key 哈希到同一协程)
+func parallelKeyed[A, B any](dc <-chan A, workers int, keyFn func(A) string, fn func(A) (B, error), emit func(parallelResult[B])) {
+	queues := make([]chan A, workers)
+	for i := range queues {
+		queues[i] = make(chan A, 1)
+	}
+	var lock sync.Mutex
+	group := sync.WaitGroup{}
+	group.Add(workers)
+	for _, queue := range queues {
+		go func(queue chan A) {
+			defer group.Done()
+			for v := range queue {
+				res := callSafely(fn, v)
+				lock.Lock()
+				emit(res)
+				lock.Unlock()
+			}
+		}(queue)
+	}
+	for v := range dc {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(keyFn(v)))
+		queues[h.Sum32()%uint32(workers)] <- v
+	}
+	for _, queue := range queues {
+		close(queue)
+	}
+	group.Wait()
+}
+
+// callSafely 执行处理方法 (panic 转换为 async.PanicError)
+func callSafely[A, B any](fn func(A) (B, error), v A) (res parallelResult[B]) {
+	defer func() {
+		if r := recover(); r != nil {
+			res.err = &async.PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	res.value, res.err = fn(v)
+	return
+}
@@ -0,0 +1,646 @@
+package flow
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Anonymouscn/go-partner/base"
+)
+
+// 磁盘队列
+// 数据按写入顺序追加到段文件 (<起始序号>.seg), 每条记录: [4 数据长度][4 CRC32][8 序号][数据];
+// 确认序号追加到确认日志 (ack.log: [8 低水位][8 确认序号]...), 低水位以下的序号均已确认.
+// 1. 至少一次投递: 读出的消息在确认前若进程退出, 重新打开队列后再次投递;
+// 2. 崩溃恢复: 打开时校验段文件, 截断末尾段未写完整的记录;
+// 3. 段压缩: 全部确认的段文件自动删除, Compact 重写已读完且确认过半的段文件并整理确认日志.
+
+var (
+	ErrQueueClosed     = errors.New("flow: disk queue is closed")              // 磁盘队列已关闭
+	ErrQueueDir        = errors.New("flow: disk queue requires a Dir")         // 磁盘队列目录未配置
+	ErrCorruptSegment  = errors.New("flow: disk queue segment is corrupted")   // 段文件损坏
+	ErrMessageNotFound = errors.New("flow: message is not delivered or acked") // 消息未投递或已确认
+)
+
+const (
+	segmentExt    = ".seg"    // 段文件扩展名
+	ackLogName    = "ack.log" // 确认日志文件名
+	recordHeadLen = 16        // 记录头长度
+)
+
+// QueueConfig 磁盘队列配置
+type QueueConfig[T any] struct {
+	Dir             string   // 队列目录 (必填)
+	Codec           Codec[T] // 编解码器 (默认 JSONCodec)
+	SegmentSize     int64    // 段文件大小上限 (默认 64MB, 超过后写入新段文件)
+	SyncWrite       bool     // 每次写入及确认后同步刷盘 (默认由操作系统刷盘, 进程崩溃不丢数据, 主机掉电可能丢失)
+	CheckpointEvery int      // 确认日志每追加多少条后整理一次 (默认 4096)
+}
+
+// Message 磁盘队列消息
+type Message[T any] struct {
+	Seq   uint64        // 消息序号
+	Item  T             // 数据
+	queue *DiskQueue[T] // 所属队列
+}
+
+// Ack 确认消息
+func (m *Message[T]) Ack() error {
+	return m.queue.Ack(m.Seq)
+}
+
+// segment 段文件
+type segment struct {
+	base  uint64   // 起始序号 (文件名)
+	last  uint64   // 最后一条记录的序号
+	count int64    // 记录数
+	size  int64    // 文件大小
+	path  string   // 文件路径
+	file  *os.File // 文件
+}
+
+// DiskQueue 磁盘队列 (实现 SpillQueue, 可作为 FlowConfig.SpillQueue 使用)
+type DiskQueue[T any] struct {
+	conf       *QueueConfig[T]     // 配置
+	lock       sync.Mutex          // 队列锁
+	segments   []*segment          // 段文件 (按起始序号排序, 最后一个为写入段)
+	rseg       *segment            // 读取段 (为空时从第一个段文件开头读取)
+	roff       int64               // 读取偏移
+	nextSeq    uint64              // 下一条记录序号
+	lowWater   uint64              // 低水位 (小于该序号的消息均已确认)
+	acked      map[uint64]struct{} // 低水位以上已确认的序号
+	inflight   map[uint64]struct{} // 已投递未确认的序号
+	pending    int64               // 待投递消息数
+	ackLog     *os.File            // 确认日志
+	ackEntries int                 // 确认日志自上次整理后追加的条数
+	notify     chan struct{}       // 写入通知
+	done       chan struct{}       // 关闭信号
+	closed     bool                // 是否已关闭
+}
+
+// OpenDiskQueue 打开磁盘队列 (目录不存在时创建; 已有数据时恢复未确认的消息; 不修改调用方配置)
+func OpenDiskQueue[T any](conf *QueueConfig[T]) (*DiskQueue[T], error) {
+	if conf == nil || conf.Dir == "" {
+		return nil, ErrQueueDir
+	}
+	res := *conf
+	conf = &res
+	if conf.Codec == nil {
+		conf.Codec = JSONCodec[T]{}
+	}
+	conf.SegmentSize = base.SetOrDefault(conf.SegmentSize, 64<<20)
+	conf.CheckpointEvery = base.SetOrDefault(conf.CheckpointEvery, 4096)
+	if err := os.MkdirAll(conf.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	q := &DiskQueue[T]{
+		conf:     conf,
+		acked:    make(map[uint64]struct{}),
+		inflight: make(map[uint64]struct{}),
+		notify:   make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	if err := q.recover(); err != nil {
+		_ = q.closeFiles()
+		return nil, err
+	}
+	return q, nil
+}
+
+// Push 写入
+func (q *DiskQueue[T]) Push(item T) error {
+	data, err := q.conf.Codec.Encode(item)
+	if err != nil {
+		return err
+	}
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	seg, err := q.writeSegment()
+	if err != nil {
+		return err
+	}
+	record := make([]byte, recordHeadLen+len(data))
+	binary.BigEndian.PutUint32(record[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint64(record[8:16], q.nextSeq)
+	copy(record[recordHeadLen:], data)
+	binary.BigEndian.PutUint32(record[4:8], crc32.ChecksumIEEE(record[8:]))
+	if _, err = seg.file.WriteAt(record, seg.size); err != nil {
+		return err
+	}
+	if q.conf.SyncWrite {
+		if err = seg.file.Sync(); err != nil {
+			return err
+		}
+	}
+	seg.size += int64(len(record))
+	seg.last = q.nextSeq
+	seg.count++
+	q.nextSeq++
+	q.pending++
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Next 读出下一条待投递消息 (无待投递消息时 ok 为 false; 消息需调用 Ack 确认)
+func (q *DiskQueue[T]) Next() (msg *Message[T], ok bool, err error) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return nil, false, ErrQueueClosed
+	}
+	for {
+		if q.rseg == nil {
+			if len(q.segments) == 0 {
+				return nil, false, nil
+			}
+			q.rseg, q.roff = q.segments[0], 0
+		}
+		if q.roff >= q.rseg.size {
+			i := q.segmentIndex(q.rseg)
+			if i+1 >= len(q.segments) {
+				return nil, false, nil
+			}
+			q.rseg, q.roff = q.segments[i+1], 0
+			continue
+		}
+		seq, data, n, err := readRecord(q.rseg.file, q.roff, q.rseg.size)
+		if err != nil {
+			return nil, false, err
+		}
+		q.roff += n
+		if q.isAcked(seq) {
+			continue
+		}
+		item, err := q.conf.Codec.Decode(data)
+		if err != nil {
+			return nil, false, err
+		}
+		q.inflight[seq] = struct{}{}
+		q.pending--
+		return &Message[T]{Seq: seq, Item: item, queue: q}, true, nil
+	}
+}
+
+// Pop 读出并立即确认 (SpillQueue 实现: 数据交给内存缓冲后即视为投递完成)
+func (q *DiskQueue[T]) Pop() (item T, ok bool, err error) {
+	msg, ok, err := q.Next()
+	if err != nil || !ok {
+		return item, ok, err
+	}
+	return msg.Item, true, msg.Ack()
+}
+
+// Ack 确认消息 (已确认的消息不会再次投递, 全部确认的段文件被删除)
+func (q *DiskQueue[T]) Ack(seq uint64) error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if _, ok := q.inflight[seq]; !ok {
+		return ErrMessageNotFound
+	}
+	var entry [8]byte
+	binary.BigEndian.PutUint64(entry[:], seq)
+	if _, err := q.ackLog.Write(entry[:]); err != nil {
+		return err
+	}
+	if q.conf.SyncWrite {
+		if err := q.ackLog.Sync(); err != nil {
+			return err
+		}
+	}
+	delete(q.inflight, seq)
+	q.acked[seq] = struct{}{}
+	q.advance()
+	if err := q.dropAcked(); err != nil {
+		return err
+	}
+	if q.ackEntries++; q.ackEntries >= q.conf.CheckpointEvery {
+		return q.checkpoint()
+	}
+	return nil
+}
+
+// Len 待投递消息数
+func (q *DiskQueue[T]) Len() int64 {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.pending
+}
+
+// Unacked 已投递未确认的消息数
+func (q *DiskQueue[T]) Unacked() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.inflight)
+}
+
+// Compact 压缩: 删除全部确认的段文件, 重写已读完且确认过半的段文件, 整理确认日志
+func (q *DiskQueue[T]) Compact() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	if err := q.dropAcked(); err != nil {
+		return err
+	}
+	// 只重写读取段之前的段文件 (其中未确认的消息均已投递, 读取偏移不受影响)
+	end := 0
+	if q.rseg != nil {
+		end = q.segmentIndex(q.rseg)
+	}
+	for _, seg := range q.segments[:end] {
+		if err := q.rewrite(seg); err != nil {
+			return err
+		}
+	}
+	return q.checkpoint()
+}
+
+// Close 关闭队列 (未确认的消息在重新打开后再次投递)
+func (q *DiskQueue[T]) Close() error {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	if q.closed {
+		return nil
+	}
+	q.closed = true
+	close(q.done)
+	return q.closeFiles()
+}
+
+// Flow 以队列为缓冲新建数据流: 生产方持续读出待投递消息写入数据流, 消费方处理完成后调用 Message.Ack 确认
+// ctx 取消后停止读出并关闭数据流 (消费方继续处理已读出的消息), 队列关闭时同样停止
+func (q *DiskQueue[T]) Flow(ctx context.Context, bufSize uint) *DataFlow[*Message[T]] {
+	f := NewDataFlow[*Message[T]](bufSize)
+	f.ProduceContext(func(ctx context.Context, dc chan<- *Message[T], ec chan<- error) error {
+		for {
+			msg, ok, err := q.Next()
+			if errors.Is(err, ErrQueueClosed) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if !ok {
+				select {
+				case <-q.notify:
+					continue
+				case <-q.done:
+					return nil
+				case <-ctx.Done():
+					return nil
+				}
+			}
+			// 未写入数据流的消息未确认, 重新打开队列后再次投递
+			if Send(ctx, dc, msg) != nil {
+				return nil
+			}
+		}
+	})
+	return f.Run(ctx)
+}
+
+// ================================ 内部实现 ================================= //
+
+// recover 恢复队列状态
+func (q *DiskQueue[T]) recover() error {
+	if err := q.loadAckLog(); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(q.conf.Dir)
+	if err != nil {
+		return err
+	}
+	var bases []uint64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, segmentExt) {
+			continue
+		}
+		if b, err := strconv.ParseUint(strings.TrimSuffix(name, segmentExt), 10, 64); err == nil {
+			bases = append(bases, b)
+		}
+	}
+	sort.Slice(bases, func(i, j int) bool { return bases[i] < bases[j] })
+	for i, b := range bases {
+		seg, err := q.scanSegment(b, i == len(bases)-1)
+		if err != nil {
+			return err
+		}
+		q.segments = append(q.segments, seg)
+	}
+	// 不存在的序号所在段文件已全部确认并删除
+	if len(q.segments) > 0 && q.segments[0].base > q.lowWater {
+		q.lowWater = q.segments[0].base
+		for seq := range q.acked {
+			if seq < q.lowWater {
+				delete(q.acked, seq)
+			}
+		}
+	}
+	q.nextSeq = q.lowWater
+	for _, seg := range q.segments {
+		if seg.base > q.nextSeq {
+			q.nextSeq = seg.base
+		}
+		if seg.count > 0 && seg.last+1 > q.nextSeq {
+			q.nextSeq = seg.last + 1
+		}
+	}
+	q.advance()
+	q.pending = 0
+	for _, seg := range q.segments {
+		var off int64
+		for off < seg.size {
+			seq, _, n, err := readRecord(seg.file, off, seg.size)
+			if err != nil {
+				return err
+			}
+			if !q.isAcked(seq) {
+				q.pending++
+			}
+			off += n
+		}
+	}
+	return q.dropAcked()
+}
+
+// scanSegment 打开并校验段文件 (末尾段中未写完整的记录被截断)
+func (q *DiskQueue[T]) scanSegment(b uint64, tail bool) (*segment, error) {
+	path := filepath.Join(q.conf.Dir, segmentName(b))
+	file, err := os.OpenFile(path, os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	seg := &segment{base: b, path: path, file: file}
+	info, err := file.Stat()
+	if err != nil {
+		return seg, err
+	}
+	for seg.size < info.Size() {
+		seq, _, n, err := readRecord(file, seg.size, info.Size())
+		if err != nil {
+			if !tail {
+				_ = file.Close()
+				return nil, fmt.Errorf("%w: %s at offset %d", ErrCorruptSegment, path, seg.size)
+			}
+			if err = file.Truncate(seg.size); err != nil {
+				_ = file.Close()
+				return nil, err
+			}
+			break
+		}
+		seg.last = seq
+		seg.count++
+		seg.size += n
+	}
+	return seg, nil
+}
+
+// readRecord 读取记录 (返回序号, 数据及记录长度; size 为段文件大小, 记录长度超出文件时视为损坏)
+func readRecord(file *os.File, off, size int64) (seq uint64, data []byte, n int64, err error) {
+	var head [recordHeadLen]byte
+	if _, err = file.ReadAt(head[:], off); err != nil {
+		return 0, nil, 0, corrupt(err)
+	}
+	length := int64(binary.BigEndian.Uint32(head[0:4]))
+	if length > size-off-recordHeadLen {
+		return 0, nil, 0, ErrCorruptSegment
+	}
+	record := make([]byte, 8+length)
+	if _, err = file.ReadAt(record[8:], off+recordHeadLen); err != nil {
+		return 0, nil, 0, corrupt(err)
+	}
+	copy(record[:8], head[8:16])
+	if crc32.ChecksumIEEE(record) != binary.BigEndian.Uint32(head[4:8]) {
+		return 0, nil, 0, ErrCorruptSegment
+	}
+	return binary.BigEndian.Uint64(head[8:16]), record[8:], int64(len(record) + 8), nil
+}
+
+// corrupt 将读取到文件末尾的错误转换为 ErrCorruptSegment
+func corrupt(err error) error {
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return ErrCorruptSegment
+	}
+	return err
+}
+
+// loadAckLog 加载确认日志 (截断未写完整的确认序号)
+func (q *DiskQueue[T]) loadAckLog() error {
+	path := filepath.Join(q.conf.Dir, ackLogName)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) >= 8 {
+		q.lowWater = binary.BigEndian.Uint64(data[:8])
+		for off := 8; off+8 <= len(data); off += 8 {
+			if seq := binary.BigEndian.Uint64(data[off : off+8]); seq >= q.lowWater {
+				q.acked[seq] = struct{}{}
+			}
+		}
+	}
+	valid := int64(len(data) / 8 * 8)
+	if q.ackLog, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+		return err
+	}
+	if valid < 8 {
+		return q.checkpoint()
+	}
+	if err = q.ackLog.Truncate(valid); err != nil {
+		return err
+	}
+	_, err = q.ackLog.Seek(valid, io.SeekStart)
+	return err
+}
+
+// checkpoint 整理确认日志 (写入临时文件后替换)
+func (q *DiskQueue[T]) checkpoint() error {
+	path := filepath.Join(q.conf.Dir, ackLogName)
+	seqs := make([]uint64, 0, len(q.acked))
+	for seq := range q.acked {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	data := make([]byte, 8*(len(seqs)+1))
+	binary.BigEndian.PutUint64(data[:8], q.lowWater)
+	for i, seq := range seqs {
+		binary.BigEndian.PutUint64(data[8*(i+1):], seq)
+	}
+	if err := writeFileSync(path+".tmp", data); err != nil {
+		return err
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return err
+	}
+	if q.ackLog != nil {
+		_ = q.ackLog.Close()
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		q.ackLog = nil
+		return err
+	}
+	q.ackLog, q.ackEntries = file, 0
+	return nil
+}
+
+// writeSegment 获取写入段 (写入段已满时新建段文件)
+func (q *DiskQueue[T]) writeSegment() (*segment, error) {
+	if n := len(q.segments); n > 0 && q.segments[n-1].size < q.conf.SegmentSize {
+		return q.segments[n-1], nil
+	}
+	path := filepath.Join(q.conf.Dir, segmentName(q.nextSeq))
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	seg := &segment{base: q.nextSeq, path: path, file: file}
+	q.segments = append(q.segments, seg)
+	return seg, nil
+}
+
+// advance 推进低水位
+func (q *DiskQueue[T]) advance() {
+	for {
+		if _, ok := q.acked[q.lowWater]; !ok || q.lowWater >= q.nextSeq {
+			return
+		}
+		delete(q.acked, q.lowWater)
+		q.lowWater++
+	}
+}
+
+// isAcked 序号是否已确认
+func (q *DiskQueue[T]) isAcked(seq uint64) bool {
+	if seq < q.lowWater {
+		return true
+	}
+	_, ok := q.acked[seq]
+	return ok
+}
+
+// dropAcked 删除全部确认的段文件 (写入段已写满时同样删除)
+func (q *DiskQueue[T]) dropAcked() error {
+	for len(q.segments) > 0 {
+		seg := q.segments[0]
+		full := len(q.segments) > 1 || seg.size >= q.conf.SegmentSize
+		if !full || seg.last >= q.lowWater {
+			return nil
+		}
+		if q.rseg == seg {
+			q.rseg, q.roff = nil, 0
+		}
+		q.segments = q.segments[1:]
+		_ = seg.file.Close()
+		if err := os.Remove(seg.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewrite 重写段文件 (仅保留未确认的记录; 确认不足一半时跳过)
+func (q *DiskQueue[T]) rewrite(seg *segment) error {
+	var records [][]byte
+	var off int64
+	for off < seg.size {
+		seq, _, n, err := readRecord(seg.file, off, seg.size)
+		if err != nil {
+			return err
+		}
+		if !q.isAcked(seq) {
+			record := make([]byte, n)
+			if _, err = seg.file.ReadAt(record, off); err != nil {
+				return err
+			}
+			records = append(records, record)
+		}
+		off += n
+	}
+	if int64(len(records))*2 > seg.count {
+		return nil
+	}
+	var data []byte
+	for _, record := range records {
+		data = append(data, record...)
+	}
+	if err := writeFileSync(seg.path+".tmp", data); err != nil {
+		return err
+	}
+	if err := os.Rename(seg.path+".tmp", seg.path); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(seg.path, os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	_ = seg.file.Close()
+	seg.file, seg.size, seg.count = file, int64(len(data)), int64(len(records))
+	return nil
+}
+
+// segmentIndex 段文件下标
+func (q *DiskQueue[T]) segmentIndex(seg *segment) int {
+	for i, s := range q.segments {
+		if s == seg {
+			return i
+		}
+	}
+	return -1
+}
+
+// closeFiles 关闭全部文件
+func (q *DiskQueue[T]) closeFiles() error {
+	var res error
+	for _, seg := range q.segments {
+		if err := seg.file.Close(); err != nil {
+			res = err
+		}
+	}
+	if q.ackLog != nil {
+		if err := q.ackLog.Close(); err != nil {
+			res = err
+		}
+	}
+	return res
+}
+
+// segmentName 段文件名
+func segmentName(b uint64) string {
+	return fmt.Sprintf("%020d%s", b, segmentExt)
+}
+
+// writeFileSync 写入文件并同步刷盘
+func writeFileSync(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(data); err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
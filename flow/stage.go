@@ -0,0 +1,161 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/base"
+)
+
+// ItemError 数据处理错误 (携带出错数据, 处理阶段及尝试次数)
+type ItemError[T any] struct {
+	Item    T      // 出错数据
+	Stage   string // 处理阶段
+	Attempt int    // 尝试次数 (从 1 开始, 即最后一次失败时的次数)
+	Err     error  // 原始错误
+}
+
+// Error 实现 error 接口
+func (e *ItemError[T]) Error() string {
+	return fmt.Sprintf("flow: stage %s failed on attempt %d: %v", e.Stage, e.Attempt, e.Err)
+}
+
+// Unwrap 原始错误
+func (e *ItemError[T]) Unwrap() error {
+	return e.Err
+}
+
+// AsItemError 从错误管道读取的错误中提取数据处理错误
+func AsItemError[T any](err error) (*ItemError[T], bool) {
+	var res *ItemError[T]
+	ok := errors.As(err, &res)
+	return res, ok
+}
+
+// RetryPolicy 重试策略 (等待时间按 Backoff * Multiplier^(n-1) 指数增长, 不超过 MaxBackoff)
+//
+// 重试在处理阶段的工作协程内原地等待: 一条数据重试期间阶段不处理后续数据 (队头阻塞),
+// 每次重试最多阻塞 MaxBackoff; 需要隔离慢数据时应配合 ParallelMap 等并行处理使用
+type RetryPolicy struct {
+	MaxAttempts int                  // 最大尝试次数 (含首次, 默认 1 即不重试)
+	Backoff     time.Duration        // 首次重试等待时间 (默认 100ms)
+	MaxBackoff  time.Duration        // 最大等待时间 (默认 10s)
+	Multiplier  float64              // 等待时间增长倍数 (默认 2)
+	Jitter      float64              // 随机抖动比例 (0~1, 等待时间在 [d*(1-Jitter), d] 内随机; 默认不抖动)
+	RetryIf     func(err error) bool // 是否重试 (默认全部重试)
+}
+
+// DeadLetter 死信处理方法 (返回错误时数据处理错误改为写入错误管道)
+type DeadLetter[T any] func(err *ItemError[T]) error
+
+// DeadLetterFlow 将死信写入数据流 (死信流需在处理阶段结束前保持开启)
+func DeadLetterFlow[T any](dlq *DataFlow[*ItemError[T]]) DeadLetter[T] {
+	return func(err *ItemError[T]) error {
+		return dlq.emit(context.Background(), err)
+	}
+}
+
+// StageConfig 处理阶段配置
+type StageConfig[T any] struct {
+	Name       string        // 阶段名称 (默认 "stage")
+	Retry      *RetryPolicy  // 重试策略 (默认不重试)
+	DeadLetter DeadLetter[T] // 死信处理 (重试耗尽的数据; 为空时数据处理错误写入错误管道)
+}
+
+// Stage 带重试的转换阶段 (重试耗尽后数据处理错误转入死信或输出流错误管道; 输入流运行上下文取消时停止等待重试)
+func Stage[A, B any](src *DataFlow[A], fn func(A) (B, error), conf *StageConfig[A]) *DataFlow[B] {
+	conf = stageConfig(conf)
+	dst := newOutput[B](src)
+	connect(src, dst, nil, func(dc <-chan A, out chan<- B, ec chan<- error, _ func()) {
+		ctx := src.Context()
+		for v := range dc {
+			res, err := runStage(ctx, conf, fn, v)
+			if err != nil {
+				conf.fail(err, ec)
+				continue
+			}
+			out <- res
+		}
+	})
+	return dst
+}
+
+// ConsumeStage 带重试的消费阶段 (重试耗尽后数据处理错误转入死信或错误管道)
+func (f *DataFlow[T]) ConsumeStage(fn func(item T) error, conf *StageConfig[T]) *DataFlow[T] {
+	conf = stageConfig(conf)
+	handle := func(item T) (struct{}, error) {
+		return struct{}{}, fn(item)
+	}
+	return f.Consume(func(dc <-chan T, ec chan<- error, args ...any) {
+		ctx := f.Context()
+		for v := range dc {
+			if _, err := runStage(ctx, conf, handle, v); err != nil {
+				conf.fail(err, ec)
+			}
+		}
+	})
+}
+
+// stageConfig 处理阶段默认配置 (复制配置及重试策略, 不修改调用方传入的配置)
+func stageConfig[T any](conf *StageConfig[T]) *StageConfig[T] {
+	res := StageConfig[T]{}
+	if conf != nil {
+		res = *conf
+	}
+	res.Name = base.SetOrDefault(res.Name, "stage")
+	retry := RetryPolicy{}
+	if res.Retry != nil {
+		retry = *res.Retry
+	}
+	retry.MaxAttempts = base.SetOrDefault(retry.MaxAttempts, 1)
+	retry.Backoff = base.SetOrDefault(retry.Backoff, 100*time.Millisecond)
+	retry.MaxBackoff = base.SetOrDefault(retry.MaxBackoff, 10*time.Second)
+	retry.Multiplier = base.SetOrDefault(retry.Multiplier, 2)
+	res.Retry = &retry
+	return &res
+}
+
+// fail 处理重试耗尽的数据 (优先转入死信)
+func (conf *StageConfig[T]) fail(err *ItemError[T], ec chan<- error) {
+	if conf.DeadLetter != nil && conf.DeadLetter(err) == nil {
+		return
+	}
+	ec <- err
+}
+
+// runStage 执行处理方法 (失败时按重试策略等待后重试, panic 视为失败)
+func runStage[A, B any](ctx context.Context, conf *StageConfig[A], fn func(A) (B, error), v A) (B, *ItemError[A]) {
+	retry := conf.Retry
+	wait := retry.Backoff
+	for attempt := 1; ; attempt++ {
+		res := callSafely(fn, v)
+		if res.err == nil {
+			return res.value, nil
+		}
+		err := &ItemError[A]{Item: v, Stage: conf.Name, Attempt: attempt, Err: res.err}
+		if attempt >= retry.MaxAttempts || (retry.RetryIf != nil && !retry.RetryIf(res.err)) {
+			return res.value, err
+		}
+		timer := time.NewTimer(retry.jitter(wait))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return res.value, err
+		}
+		if wait = time.Duration(float64(wait) * retry.Multiplier); wait > retry.MaxBackoff {
+			wait = retry.MaxBackoff
+		}
+	}
+}
+
+// jitter 随机抖动等待时间
+func (p *RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return d
+	}
+	return d - time.Duration(rand.Float64()*p.Jitter*float64(d))
+}
@@ -20,6 +20,7 @@ import (
 	customerror "github.com/Anonymouscn/go-partner/error"
 	iotools "github.com/Anonymouscn/go-partner/io"
 	"github.com/Anonymouscn/go-partner/net"
+	restful_model "github.com/Anonymouscn/go-partner/restful/model"
 	"github.com/bytedance/sonic"
 )
 
@@ -33,11 +34,12 @@ type RestClient struct {
 
 // RestClientConfig RestClient 配置
 type RestClientConfig struct {
-	EnableRetry    bool           // 启用重试
-	MaxRetry       int            // 最大重试次数
-	RetryDelay     time.Duration  // 重试间隔时间
-	RequestTimeout time.Duration  // 超时时间
-	Transport      http.Transport // transport 配置
+	EnableRetry    bool            // 启用重试
+	MaxRetry       int             // 最大重试次数
+	RetryDelay     time.Duration   // 重试间隔时间
+	RequestTimeout time.Duration   // 超时时间
+	Transport      http.Transport  // transport 配置
+	Redirect       *RedirectPolicy // 重定向策略 (nil 使用默认策略)
 }
 
 // ParamsConfig 参数配置
@@ -71,6 +73,8 @@ func NewRestClient() *RestClient {
 	}
 	// 初始化 headers map
 	client.request.req.Header = make(http.Header)
+	// 接管重定向策略
+	client.client.CheckRedirect = client.checkRedirect
 	return client
 }
 
@@ -607,9 +611,13 @@ func (rc *RestClient) executeRequest() (*Response, error) {
 	if err != nil {
 		return nil, err
 	}
+	if resp.StatusCode >= 300 && resp.StatusCode < 400 && rc.isRedirectDisabled() { // 禁用重定向时保留 3xx 响应
+		return rc.buildResponse(resp, body), nil
+	}
 	if resp.StatusCode >= 300 { // 非正常响应
 		return nil, &customerror.RequestFail{
 			Details: "raw response: " + string(body),
+			Cause:   rc.parseProblem(resp, body),
 		}
 	} else if !rc.isJsonResponse(body) { // 非 json 响应
 		return nil, &customerror.NoRegularJsonResponse{
@@ -617,6 +625,26 @@ func (rc *RestClient) executeRequest() (*Response, error) {
 		}
 	}
 	// 正常响应
+	return rc.buildResponse(resp, body), nil
+}
+
+// parseProblem 解析 problem+json 问题详情 (非 problem+json 响应返回 nil)
+func (rc *RestClient) parseProblem(resp *http.Response, body []byte) error {
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), restful_model.ProblemContentType) {
+		return nil
+	}
+	p := &restful_model.Problem{}
+	if err := sonic.Unmarshal(body, p); err != nil {
+		return nil
+	}
+	if p.Status == 0 {
+		p.Status = resp.StatusCode
+	}
+	return p
+}
+
+// buildResponse 构建响应
+func (rc *RestClient) buildResponse(resp *http.Response, body []byte) *Response {
 	return &Response{
 		StatusCode: resp.StatusCode,
 		Proto:      resp.Proto,
@@ -624,7 +652,13 @@ func (rc *RestClient) executeRequest() (*Response, error) {
 		Headers:    &resp.Header,
 		Request:    &rc.request.req,
 		TLS:        resp.TLS,
-	}, nil
+		Redirects:  buildRedirectChain(resp),
+	}
+}
+
+// isRedirectDisabled 是否禁用重定向
+func (rc *RestClient) isRedirectDisabled() bool {
+	return rc.conf.Redirect != nil && rc.conf.Redirect.Disable
 }
 
 // handleData 处理动态参数数据
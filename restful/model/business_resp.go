@@ -17,6 +17,11 @@ func SuccessWithData(data any) *Result[any] {
 	return ReplyWithData(200, "Success", data)
 }
 
+// InvalidParams 参数校验失败响应 (带校验错误详情)
+func InvalidParams(details any) *Result[any] {
+	return ReplyWithData(CodeInvalidParams.Code, CodeInvalidParams.Message, details)
+}
+
 // Reply 普通响应
 func Reply(code int, msg string) *Result[any] {
 	return ReplyWithData(code, msg, nil)
@@ -0,0 +1,169 @@
+package restful_model
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/Anonymouscn/go-partner/validate"
+)
+
+// ErrorCode 业务错误码
+type ErrorCode struct {
+	Code    int    // 业务状态码
+	Status  int    // http 状态码
+	Message string // 默认错误信息
+	I18nKey string // 国际化键
+	Type    string // problem+json 类型 URI (为空时使用 about:blank)
+}
+
+func (c *ErrorCode) Error() string {
+	return fmt.Sprintf("%d: %s", c.Code, c.Message)
+}
+
+// TranslateFn 国际化翻译方法
+// locale: 语言标签 (如 zh-CN), key: 国际化键; 返回翻译结果及是否命中
+type TranslateFn func(locale, key string) (string, bool)
+
+var (
+	codesLock  sync.RWMutex               // 错误码表读写锁
+	codes      = make(map[int]*ErrorCode) // 错误码表 map[业务状态码]错误码
+	translator TranslateFn                // 国际化翻译方法
+)
+
+// 内置错误码 (业务状态码与 http 状态码一致)
+var (
+	CodeInvalidParams    = RegisterErrorCode(400, http.StatusBadRequest, "Invalid Params", "error.invalid_params")
+	CodeUnauthorized     = RegisterErrorCode(401, http.StatusUnauthorized, "Unauthorized", "error.unauthorized")
+	CodeForbidden        = RegisterErrorCode(403, http.StatusForbidden, "Forbidden", "error.forbidden")
+	CodeNotFound         = RegisterErrorCode(404, http.StatusNotFound, "Not Found", "error.not_found")
+	CodeMethodNotAllowed = RegisterErrorCode(405, http.StatusMethodNotAllowed, "Method Not Allowed", "error.method_not_allowed")
+	CodeConflict         = RegisterErrorCode(409, http.StatusConflict, "Conflict", "error.conflict")
+	CodeInternal         = RegisterErrorCode(500, http.StatusInternalServerError, "Internal Server Error", "error.internal")
+)
+
+// RegisterErrorCode 注册错误码 (业务状态码重复注册时 panic)
+func RegisterErrorCode(code, status int, msg, i18nKey string) *ErrorCode {
+	codesLock.Lock()
+	defer codesLock.Unlock()
+	if _, exist := codes[code]; exist {
+		panic(fmt.Sprintf("error code %d is already registered", code))
+	}
+	c := &ErrorCode{
+		Code:    code,
+		Status:  status,
+		Message: msg,
+		I18nKey: i18nKey,
+	}
+	codes[code] = c
+	return c
+}
+
+// LookupErrorCode 查找错误码
+func LookupErrorCode(code int) (*ErrorCode, bool) {
+	codesLock.RLock()
+	defer codesLock.RUnlock()
+	c, ok := codes[code]
+	return c, ok
+}
+
+// SetTranslator 设置国际化翻译方法
+func SetTranslator(fn TranslateFn) {
+	codesLock.Lock()
+	defer codesLock.Unlock()
+	translator = fn
+}
+
+// Localize 获取本地化错误信息 (未命中时返回默认错误信息)
+func (c *ErrorCode) Localize(locale string) string {
+	codesLock.RLock()
+	fn := translator
+	codesLock.RUnlock()
+	if fn != nil && c.I18nKey != "" && locale != "" {
+		if msg, ok := fn(locale, c.I18nKey); ok {
+			return msg
+		}
+	}
+	return c.Message
+}
+
+// CodeError 携带错误码的业务错误
+type CodeError struct {
+	Code    *ErrorCode // 错误码
+	Message string     // 错误信息 (为空时使用错误码默认信息)
+	Details any        // 错误详情
+	Cause   error      // 原始错误
+}
+
+// NewError 新建业务错误
+func NewError(code *ErrorCode, msg string) *CodeError {
+	return &CodeError{Code: code, Message: msg}
+}
+
+// WrapError 包装原始错误为业务错误
+func WrapError(code *ErrorCode, err error) *CodeError {
+	return &CodeError{Code: code, Cause: err}
+}
+
+// WithDetails 设置错误详情
+func (err *CodeError) WithDetails(details any) *CodeError {
+	err.Details = details
+	return err
+}
+
+func (err *CodeError) Error() string {
+	msg := err.Message
+	if msg == "" {
+		msg = err.Code.Message
+	}
+	if err.Cause != nil {
+		return fmt.Sprintf("%d: %s - %v", err.Code.Code, msg, err.Cause)
+	}
+	return fmt.Sprintf("%d: %s", err.Code.Code, msg)
+}
+
+// Unwrap 获取原始错误
+func (err *CodeError) Unwrap() error {
+	return err.Cause
+}
+
+// resolveError 解析错误对应的错误码, 错误信息及详情
+// locale: 语言标签, 非业务错误统一视为内部错误 (不暴露原始错误信息)
+func resolveError(err error, locale string) (*ErrorCode, string, any) {
+	var (
+		ce   *CodeError
+		code *ErrorCode
+		errs validate.ValidationErrors
+	)
+	switch {
+	case errors.As(err, &ce):
+		msg := ce.Message
+		if msg == "" {
+			msg = ce.Code.Localize(locale)
+		}
+		return ce.Code, msg, ce.Details
+	case errors.As(err, &code):
+		return code, code.Localize(locale), nil
+	case errors.As(err, &errs):
+		return CodeInvalidParams, CodeInvalidParams.Localize(locale), errs
+	}
+	return CodeInternal, CodeInternal.Localize(locale), nil
+}
+
+// FromError 根据错误构建业务结果 (支持 CodeError, ErrorCode 及 validate.ValidationErrors)
+func FromError(err error) *Result[any] {
+	return FromErrorWithLocale(err, "")
+}
+
+// FromErrorWithLocale 根据错误构建本地化业务结果
+func FromErrorWithLocale(err error, locale string) *Result[any] {
+	code, msg, details := resolveError(err, locale)
+	return ReplyWithData(code.Code, msg, details)
+}
+
+// StatusOf 获取错误对应的 http 状态码
+func StatusOf(err error) int {
+	code, _, _ := resolveError(err, "")
+	return code.Status
+}
@@ -0,0 +1,147 @@
+package restful_model
+
+// PageResult 分页结果 (同时支持偏移分页与游标分页)
+type PageResult[T any] struct {
+	Items      []T    `json:"items"`                 // 当前页数据
+	Total      int64  `json:"total"`                 // 数据总数 (游标分页未知时为 -1)
+	Page       int    `json:"page,omitempty"`        // 当前页码 (偏移分页, 从 1 开始)
+	Size       int    `json:"size"`                  // 每页数量
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标 (游标分页, 为空表示没有下一页)
+	HasMore    bool   `json:"has_more"`              // 是否存在下一页
+}
+
+// PageQuery 分页查询参数
+type PageQuery struct {
+	Page   int    `json:"page" form:"page"`     // 页码 (偏移分页, 从 1 开始)
+	Size   int    `json:"size" form:"size"`     // 每页数量
+	Cursor string `json:"cursor" form:"cursor"` // 当前游标 (游标分页)
+}
+
+// 分页默认值
+const (
+	DefaultPageSize = 20  // 默认每页数量
+	MaxPageSize     = 500 // 最大每页数量
+)
+
+// Normalize 规范化分页参数 (页码最小为 1, 每页数量限制在 [1, MaxPageSize])
+func (q *PageQuery) Normalize() *PageQuery {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Size <= 0 {
+		q.Size = DefaultPageSize
+	}
+	if q.Size > MaxPageSize {
+		q.Size = MaxPageSize
+	}
+	return q
+}
+
+// Offset 偏移量 (偏移分页)
+func (q *PageQuery) Offset() int {
+	return (q.Page - 1) * q.Size
+}
+
+// IsCursor 是否为游标分页
+func (q *PageQuery) IsCursor() bool {
+	return q.Cursor != ""
+}
+
+// NewPage 新建偏移分页结果
+func NewPage[T any](items []T, total int64, page, size int) *PageResult[T] {
+	if items == nil {
+		items = make([]T, 0)
+	}
+	return &PageResult[T]{
+		Items:   items,
+		Total:   total,
+		Page:    page,
+		Size:    size,
+		HasMore: int64(page)*int64(size) < total,
+	}
+}
+
+// NewCursorPage 新建游标分页结果
+// nextCursor: 下一页游标 (为空表示没有下一页), total: 数据总数 (未知时传 -1)
+func NewCursorPage[T any](items []T, nextCursor string, size int, total int64) *PageResult[T] {
+	if items == nil {
+		items = make([]T, 0)
+	}
+	return &PageResult[T]{
+		Items:      items,
+		Total:      total,
+		Size:       size,
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	}
+}
+
+// EmptyPage 新建空分页结果
+func EmptyPage[T any](q *PageQuery) *PageResult[T] {
+	if q.IsCursor() {
+		return NewCursorPage[T](nil, "", q.Size, 0)
+	}
+	return NewPage[T](nil, 0, q.Page, q.Size)
+}
+
+// TotalPages 总页数 (总数未知时返回 -1)
+func (p *PageResult[T]) TotalPages() int64 {
+	if p.Total < 0 {
+		return -1
+	}
+	if p.Size <= 0 {
+		return 0
+	}
+	return (p.Total + int64(p.Size) - 1) / int64(p.Size)
+}
+
+// MapPage 分页结果类型转换 (保留分页信息)
+func MapPage[A, B any](p *PageResult[A], fn func(A) B) *PageResult[B] {
+	if p == nil {
+		return nil
+	}
+	items := make([]B, 0, len(p.Items))
+	for _, item := range p.Items {
+		items = append(items, fn(item))
+	}
+	return &PageResult[B]{
+		Items:      items,
+		Total:      p.Total,
+		Page:       p.Page,
+		Size:       p.Size,
+		NextCursor: p.NextCursor,
+		HasMore:    p.HasMore,
+	}
+}
+
+// MapPageE 分页结果类型转换 (转换失败时返回错误)
+func MapPageE[A, B any](p *PageResult[A], fn func(A) (B, error)) (*PageResult[B], error) {
+	if p == nil {
+		return nil, nil
+	}
+	items := make([]B, 0, len(p.Items))
+	for _, item := range p.Items {
+		b, err := fn(item)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, b)
+	}
+	return &PageResult[B]{
+		Items:      items,
+		Total:      p.Total,
+		Page:       p.Page,
+		Size:       p.Size,
+		NextCursor: p.NextCursor,
+		HasMore:    p.HasMore,
+	}, nil
+}
+
+// SuccessWithPage 响应成功 (带分页数据)
+func SuccessWithPage[T any](p *PageResult[T]) *Result[*PageResult[T]] {
+	return &Result[*PageResult[T]]{
+		Code:    200,
+		Message: "Success",
+		Data:    p,
+	}
+}
@@ -0,0 +1,98 @@
+package restful_model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProblemContentType RFC 7807 problem+json 内容类型
+const ProblemContentType = "application/problem+json"
+
+// Problem RFC 7807 问题详情 (application/problem+json)
+type Problem struct {
+	Type     string `json:"type,omitempty"`     // 问题类型 URI
+	Title    string `json:"title,omitempty"`    // 问题概要
+	Status   int    `json:"status,omitempty"`   // http 状态码
+	Detail   string `json:"detail,omitempty"`   // 问题详情
+	Instance string `json:"instance,omitempty"` // 问题实例 URI
+	Code     int    `json:"code,omitempty"`     // 业务状态码 (扩展字段)
+	Errors   any    `json:"errors,omitempty"`   // 错误详情 (扩展字段)
+}
+
+func (p *Problem) Error() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("%d %s", p.Status, p.Title))
+	if p.Detail != "" {
+		builder.WriteString(" - ")
+		builder.WriteString(p.Detail)
+	}
+	return builder.String()
+}
+
+// NewProblem 根据错误码新建问题详情
+func NewProblem(code *ErrorCode, detail string) *Problem {
+	typ := code.Type
+	if typ == "" {
+		typ = "about:blank"
+	}
+	return &Problem{
+		Type:   typ,
+		Title:  code.Message,
+		Status: code.Status,
+		Detail: detail,
+		Code:   code.Code,
+	}
+}
+
+// ProblemFromError 根据错误构建问题详情 (支持 CodeError, ErrorCode 及 validate.ValidationErrors)
+func ProblemFromError(err error) *Problem {
+	return ProblemFromErrorWithLocale(err, "")
+}
+
+// ProblemFromErrorWithLocale 根据错误构建本地化问题详情
+func ProblemFromErrorWithLocale(err error, locale string) *Problem {
+	code, msg, details := resolveError(err, locale)
+	p := NewProblem(code, "")
+	p.Title = code.Localize(locale)
+	if msg != p.Title {
+		p.Detail = msg
+	}
+	p.Errors = details
+	return p
+}
+
+// ToProblem 业务结果转问题详情 (status: http 状态码)
+func (r *Result[T]) ToProblem(status int) *Problem {
+	p := &Problem{
+		Type:   "about:blank",
+		Title:  r.Message,
+		Status: status,
+		Code:   r.Code,
+	}
+	if code, ok := LookupErrorCode(r.Code); ok {
+		p.Title = code.Message
+		if code.Type != "" {
+			p.Type = code.Type
+		}
+		if r.Message != code.Message {
+			p.Detail = r.Message
+		}
+	}
+	if any(r.Data) != nil {
+		p.Errors = r.Data
+	}
+	return p
+}
+
+// ToResult 问题详情转业务结果
+func (p *Problem) ToResult() *Result[any] {
+	code := p.Code
+	if code == 0 {
+		code = p.Status
+	}
+	msg := p.Detail
+	if msg == "" {
+		msg = p.Title
+	}
+	return ReplyWithData(code, msg, p.Errors)
+}
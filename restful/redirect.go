@@ -0,0 +1,146 @@
+package restful
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	customerror "github.com/Anonymouscn/go-partner/error"
+)
+
+// DefaultMaxRedirects 默认最大重定向次数 (与 net/http 默认策略一致)
+const DefaultMaxRedirects = 10
+
+// RedirectPolicy 重定向策略
+type RedirectPolicy struct {
+	Disable        bool     // 禁用重定向 (直接返回 3xx 响应)
+	MaxRedirects   int      // 最大重定向次数 (<= 0 时使用 DefaultMaxRedirects)
+	SameHostOnly   bool     // 仅允许同主机重定向 (主机名及端口均相同)
+	TrustedHosts   []string // 受信主机名 (不含端口, 匹配任意端口), 跨主机重定向到受信主机时转发全部原始请求头
+	ForwardHeaders []string // 跨主机重定向时强制转发的原始请求头 (如 Authorization)
+	StripHeaders   []string // 跨主机重定向时强制移除的请求头 (优先于 ForwardHeaders)
+}
+
+// RedirectRecord 重定向记录
+type RedirectRecord struct {
+	Method     string // 请求方法
+	URL        string // 请求地址
+	StatusCode int    // 该次请求的响应状态码
+}
+
+// ApplyRedirectPolicy 应用重定向策略 (nil 恢复默认策略)
+func (rc *RestClient) ApplyRedirectPolicy(policy *RedirectPolicy) *RestClient {
+	rc.conf.Redirect = policy
+	return rc
+}
+
+// DisableRedirect 禁用重定向
+func (rc *RestClient) DisableRedirect() *RestClient {
+	rc.redirectPolicy().Disable = true
+	return rc
+}
+
+// SetMaxRedirects 设置最大重定向次数
+func (rc *RestClient) SetMaxRedirects(limit int) *RestClient {
+	rc.redirectPolicy().MaxRedirects = limit
+	return rc
+}
+
+// redirectPolicy 获取重定向策略 (不存在则初始化)
+func (rc *RestClient) redirectPolicy() *RedirectPolicy {
+	if rc.conf.Redirect == nil {
+		rc.conf.Redirect = &RedirectPolicy{}
+	}
+	return rc.conf.Redirect
+}
+
+// checkRedirect 重定向检查 (http.Client.CheckRedirect 实现)
+// req: 即将发送的重定向请求, via: 已发送请求 (按时间顺序, via[0] 为原始请求)
+func (rc *RestClient) checkRedirect(req *http.Request, via []*http.Request) error {
+	policy := rc.conf.Redirect
+	if policy == nil {
+		if len(via) >= DefaultMaxRedirects {
+			return &customerror.RedirectRejected{
+				Details: fmt.Sprintf("stopped after %d redirects", DefaultMaxRedirects),
+			}
+		}
+		return nil
+	}
+	if policy.Disable {
+		return http.ErrUseLastResponse
+	}
+	limit := policy.MaxRedirects
+	if limit <= 0 {
+		limit = DefaultMaxRedirects
+	}
+	if len(via) >= limit {
+		return &customerror.RedirectRejected{
+			Details: fmt.Sprintf("stopped after %d redirects", limit),
+		}
+	}
+	origin := via[0]
+	if sameHost(origin, req) {
+		return nil
+	}
+	if policy.SameHostOnly {
+		return &customerror.RedirectRejected{
+			Details: fmt.Sprintf("cross-host redirect from %v to %v", origin.URL.Host, req.URL.Host),
+		}
+	}
+	// 跨主机请求头转发处理
+	if policy.isTrustedHost(req.URL.Hostname()) {
+		copyHeaders(req.Header, origin.Header, nil)
+	} else if len(policy.ForwardHeaders) > 0 {
+		copyHeaders(req.Header, origin.Header, policy.ForwardHeaders)
+	}
+	for _, h := range policy.StripHeaders {
+		req.Header.Del(h)
+	}
+	return nil
+}
+
+// isTrustedHost 是否是受信主机 (按主机名比较, 忽略端口)
+func (policy *RedirectPolicy) isTrustedHost(host string) bool {
+	for _, h := range policy.TrustedHosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameHost 是否为同一主机 (主机名 + 端口; 受信主机仅比较主机名, 见 isTrustedHost)
+func sameHost(a, b *http.Request) bool {
+	return strings.EqualFold(a.URL.Host, b.URL.Host)
+}
+
+// copyHeaders 复制请求头 (keys 为空时复制全部)
+func copyHeaders(dst, src http.Header, keys []string) {
+	if len(keys) == 0 {
+		for k, v := range src {
+			dst[k] = append([]string(nil), v...)
+		}
+		return
+	}
+	for _, k := range keys {
+		if v := src.Values(k); len(v) > 0 {
+			dst[http.CanonicalHeaderKey(k)] = append([]string(nil), v...)
+		}
+	}
+}
+
+// buildRedirectChain 根据最终响应构建重定向链 (按时间顺序, 不包含最终请求)
+func buildRedirectChain(resp *http.Response) []*RedirectRecord {
+	chain := make([]*RedirectRecord, 0)
+	if resp == nil || resp.Request == nil {
+		return chain
+	}
+	for r := resp.Request.Response; r != nil && r.Request != nil; r = r.Request.Response {
+		chain = append([]*RedirectRecord{{
+			Method:     r.Request.Method,
+			URL:        r.Request.URL.String(),
+			StatusCode: r.StatusCode,
+		}}, chain...)
+	}
+	return chain
+}
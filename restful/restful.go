@@ -46,4 +46,5 @@ type Response struct {
 	TLS        *tls.ConnectionState // tls 连接状态
 	Err        error                // 执行错误
 	Time       time.Duration        // 响应用时
+	Redirects  []*RedirectRecord    // 重定向链 (按时间顺序, 不包含最终请求)
 }
@@ -0,0 +1,148 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  debounce/throttle/singleflight 测试                                              //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.03 10:42:18                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestDebounce 防抖测试
+func TestDebounce(t *testing.T) {
+	clock := async.NewFakeClock(time.Now())
+	res := make(chan int, 4)
+	d := async.Debounce(context.Background(), 100*time.Millisecond, func(ctx context.Context, v int) {
+		res <- v
+	}, &async.DebounceConfig{Clock: clock, MaxWait: 250 * time.Millisecond})
+	for i := 1; i <= 3; i++ {
+		d.Call(i)
+		clock.Advance(50 * time.Millisecond)
+	}
+	clock.Advance(50 * time.Millisecond)
+	if v := <-res; v != 3 {
+		t.Errorf("expected last value 3, got %v", v)
+	}
+	// 持续调用时最迟在 MaxWait 后执行
+	for i := 4; i <= 8; i++ {
+		d.Call(i)
+		clock.Advance(50 * time.Millisecond)
+	}
+	if v := <-res; v != 8 {
+		t.Errorf("expected max wait flush with 8, got %v", v)
+	}
+	d.Call(9)
+	d.Flush()
+	if v := <-res; v != 9 {
+		t.Errorf("expected flushed value 9, got %v", v)
+	}
+	d.Call(10)
+	d.Cancel()
+	if d.Pending() {
+		t.Error("expected no pending call after cancel")
+	}
+}
+
+// TestThrottle 节流测试
+func TestThrottle(t *testing.T) {
+	clock := async.NewFakeClock(time.Now())
+	ctx, cancel := context.WithCancel(context.Background())
+	res := make(chan int, 4)
+	th := async.Throttle(ctx, time.Second, func(ctx context.Context, v int) {
+		res <- v
+	}, &async.DebounceConfig{Clock: clock})
+	th.Call(1)
+	th.Call(2)
+	th.Call(3)
+	if v := <-res; v != 1 {
+		t.Errorf("expected leading value 1, got %v", v)
+	}
+	clock.Advance(time.Second)
+	if v := <-res; v != 3 {
+		t.Errorf("expected trailing value 3, got %v", v)
+	}
+	th.Call(4)
+	cancel()
+	clock.Advance(time.Second)
+	th.Call(5)
+	select {
+	case v := <-res:
+		t.Errorf("unexpected call after cancel: %v", v)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+// TestSingleFlight 合并调用测试
+func TestSingleFlight(t *testing.T) {
+	clock := async.NewFakeClock(time.Now())
+	g := async.NewSingleFlight[string, int](&async.SingleFlightConfig{TTL: time.Minute, Clock: clock})
+	var calls int64
+	release := make(chan struct{})
+	fn := func(ctx context.Context) (int, error) {
+		<-release
+		return int(atomic.AddInt64(&calls, 1)), nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if v, _, err := g.Do(context.Background(), "k", fn); v != 1 || err != nil {
+				t.Errorf("unexpected result: %v %v", v, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	// TTL 内命中缓存
+	if v, shared, _ := g.Do(context.Background(), "k", fn); v != 1 || !shared {
+		t.Errorf("expected cached result, got %v shared=%v", v, shared)
+	}
+	clock.Advance(time.Minute)
+	if v, _, _ := g.Do(context.Background(), "k", fn); v != 2 {
+		t.Errorf("expected refreshed result, got %v", v)
+	}
+	// 出错后立即遗忘
+	boom := errors.New("boom")
+	for i := 0; i < 2; i++ {
+		if _, _, err := g.Do(context.Background(), "e", func(ctx context.Context) (int, error) {
+			atomic.AddInt64(&calls, 1)
+			return 0, boom
+		}); !errors.Is(err, boom) {
+			t.Errorf("expected boom, got %v", err)
+		}
+	}
+	if atomic.LoadInt64(&calls) != 4 {
+		t.Errorf("expected error not cached, calls=%d", calls)
+	}
+	// 调用方全部放弃时取消执行
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	cancelled := make(chan struct{})
+	_, _, err := g.Do(ctx, "slow", func(ctx context.Context) (int, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return 0, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	<-cancelled
+}
@@ -0,0 +1,105 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  future 测试                                                                      //
+//  @author anonymous                                                               //
+//  @updated_at 2024.11.29 10:17:45                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//  2. bench test:                                                                  //
+//     $ go test -benchmem -run=^$ -bench ^<$function_name>$ -count=<$count> -v     //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// delayed 延迟返回结果
+func delayed[T any](d time.Duration, v T, err error) *async.Future[T] {
+	return async.Go(func() (T, error) {
+		time.Sleep(d)
+		return v, err
+	})
+}
+
+// TestFutureMap 结果转换测试
+func TestFutureMap(t *testing.T) {
+	f := async.Map(async.Go(func() (int, error) { return 21, nil }), func(v int) (string, error) {
+		return strconv.Itoa(v * 2), nil
+	})
+	g := async.FlatMap(f, func(s string) *async.Future[int] {
+		return async.Go(func() (int, error) { return strconv.Atoi(s + "0") })
+	})
+	if v, err := g.Await(); err != nil || v != 420 {
+		t.Errorf("unexpected result: %v %v", v, err)
+	}
+	p := async.Go(func() (int, error) { panic("boom") })
+	var pe *async.PanicError
+	if _, err := async.Map(p, func(v int) (int, error) { return v, nil }).Await(); !errors.As(err, &pe) {
+		t.Errorf("expected panic error, got %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := delayed(time.Second, 1, nil).AwaitCtx(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
+
+// TestFutureCombinators 组合方法测试
+func TestFutureCombinators(t *testing.T) {
+	boom := errors.New("boom")
+	if v, err := async.All(delayed(20*time.Millisecond, 1, nil), delayed(0, 2, nil)).Await(); err != nil || v[0] != 1 || v[1] != 2 {
+		t.Errorf("unexpected all result: %v %v", v, err)
+	}
+	if _, err := async.All(delayed(time.Second, 1, nil), delayed(0, 2, boom)).AwaitCtx(timeoutCtx(t)); !errors.Is(err, boom) {
+		t.Errorf("all should fail fast, got %v", err)
+	}
+	if v, err := async.Any(delayed(0, 1, boom), delayed(20*time.Millisecond, 2, nil)).Await(); err != nil || v != 2 {
+		t.Errorf("unexpected any result: %v %v", v, err)
+	}
+	var agg *async.AggregateError
+	if _, err := async.Any(delayed(0, 1, boom), delayed(0, 2, boom)).Await(); !errors.As(err, &agg) || len(agg.Errors) != 2 {
+		t.Errorf("expected aggregate error, got %v", err)
+	}
+	if _, err := async.Race(delayed(50*time.Millisecond, 1, nil), delayed(0, 2, boom)).Await(); !errors.Is(err, boom) {
+		t.Errorf("unexpected race result: %v", err)
+	}
+	settled, _ := async.AllSettled(delayed(0, 1, nil), delayed(0, 2, boom)).Await()
+	if settled[0].Value != 1 || settled[0].Err != nil || !errors.Is(settled[1].Err, boom) {
+		t.Errorf("unexpected settled result: %+v", settled)
+	}
+}
+
+// TestFuturePromiseInterop Future 与 Promise 互操作测试
+func TestFuturePromiseInterop(t *testing.T) {
+	p := async.NewPromise().
+		Then(async.Go(func() (int, error) { return 6, nil }).Action()).
+		Then(async.Typed(func(v int) (int, error) { return v * 7, nil }))
+	if v, err := async.FromPromise[int](p).Await(); err != nil || v != 42 {
+		t.Errorf("unexpected result: %v %v", v, err)
+	}
+	p = async.NewPromise().
+		Then(async.Action{Fn: func() string { return "x" }}).
+		Then(async.Typed(func(v int) (int, error) { return v, nil }))
+	if _, err := p.Get(); err == nil {
+		t.Error("expected type mismatch error")
+	}
+}
+
+// timeoutCtx 测试超时上下文
+func timeoutCtx(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	t.Cleanup(cancel)
+	return ctx
+}
@@ -14,3 +14,127 @@ package async
 //                                                                                  //
 //                                                                                  //
 // ================================================================================ //
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// TestGoPoolSubmit 协程池提交任务测试
+func TestGoPoolSubmit(t *testing.T) {
+	p := async.NewGoPool(&async.GoPoolConfig{Capacity: 4})
+	futures := make([]*async.TaskFuture, 0)
+	for i := 0; i < 100; i++ {
+		n := i
+		f, err := p.Submit(func() (any, error) {
+			return n * n, nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		futures = append(futures, f)
+	}
+	for i, f := range futures {
+		if v, err := f.Get(); err != nil || v.(int) != i*i {
+			t.Errorf("unexpected result of task %d: %v %v", i, v, err)
+		}
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := p.Stats(); stats.Completed != 100 || stats.Workers != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+	if _, err := p.Submit(func() (any, error) { return nil, nil }); !errors.Is(err, async.ErrPoolClosed) {
+		t.Errorf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+// TestGoPoolTrySubmit 协程池尝试提交任务测试
+func TestGoPoolTrySubmit(t *testing.T) {
+	p := async.NewGoPool(&async.GoPoolConfig{Capacity: 1, QueueSize: 1})
+	block := make(chan struct{})
+	started := make(chan struct{})
+	_, _ = p.Submit(func() (any, error) {
+		close(started)
+		<-block
+		return nil, nil
+	})
+	<-started
+	if _, err := p.TrySubmit(func() (any, error) { return nil, nil }); err != nil {
+		t.Fatalf("expected queued task, got %v", err)
+	}
+	if _, err := p.TrySubmit(func() (any, error) { return nil, nil }); !errors.Is(err, async.ErrPoolFull) {
+		t.Errorf("expected ErrPoolFull, got %v", err)
+	}
+	close(block)
+	_ = p.Shutdown(context.Background())
+}
+
+// TestGoPoolPanic 协程池 panic 恢复测试
+func TestGoPoolPanic(t *testing.T) {
+	var handled int32
+	p := async.NewGoPool(&async.GoPoolConfig{
+		Capacity: 2,
+		PanicHandler: func(err *async.PanicError) {
+			atomic.AddInt32(&handled, 1)
+		},
+	})
+	f, _ := p.Submit(func() (any, error) {
+		panic("boom")
+	})
+	_, err := f.Get()
+	var pe *async.PanicError
+	if !errors.As(err, &pe) || pe.Value != "boom" || atomic.LoadInt32(&handled) != 1 {
+		t.Errorf("unexpected panic error: %v", err)
+	}
+	_ = p.Shutdown(context.Background())
+	if stats := p.Stats(); stats.Panicked != 1 || stats.Failed != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+// TestGoPoolResize 协程池动态扩缩容及空闲回收测试
+func TestGoPoolResize(t *testing.T) {
+	p := async.NewGoPool(&async.GoPoolConfig{Capacity: 1, IdleTimeout: 20 * time.Millisecond})
+	var running, peak int32
+	block := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		_, _ = p.Submit(func() (any, error) {
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+					break
+				}
+			}
+			<-block
+			atomic.AddInt32(&running, -1)
+			return nil, nil
+		})
+	}
+	p.Resize(4)
+	time.Sleep(20 * time.Millisecond)
+	if stats := p.Stats(); stats.Workers != 4 {
+		t.Errorf("expected 4 workers after resize, got %+v", stats)
+	}
+	close(block)
+	// 等待空闲协程回收
+	time.Sleep(100 * time.Millisecond)
+	if stats := p.Stats(); stats.Workers != 0 || stats.Completed != 4 {
+		t.Errorf("expected idle workers to be reaped, got %+v", stats)
+	}
+	if atomic.LoadInt32(&peak) != 4 {
+		t.Errorf("expected 4 concurrent tasks, got %d", peak)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := p.Shutdown(ctx); err != nil {
+		t.Error(err)
+	}
+}
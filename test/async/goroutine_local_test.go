@@ -0,0 +1,92 @@
+package async
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  goroutine local 测试                                                             //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.01 11:05:27                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestGoLocal 协程本地变量测试
+func TestGoLocal(t *testing.T) {
+	traceID := async.NewInheritableGoLocal[string]()
+	userID := async.NewGoLocal[int]()
+	traceID.Set("trace-1")
+	userID.Set(42)
+	defer async.ClearGoLocals()
+	// 其他协程互不可见
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, ok := traceID.Get(); ok {
+			t.Error("plain goroutine should not see parent locals")
+		}
+		traceID.Set("trace-2")
+		async.ClearGoLocals()
+	}()
+	wg.Wait()
+	if v, _ := traceID.Get(); v != "trace-1" {
+		t.Errorf("unexpected trace id: %v", v)
+	}
+	// 通过 GoWithLocals 继承可继承变量
+	wg.Add(1)
+	async.GoWithLocals(func() {
+		defer wg.Done()
+		if v, _ := traceID.Get(); v != "trace-1" {
+			t.Errorf("expected inherited trace id, got %v", v)
+		}
+		if _, ok := userID.Get(); ok {
+			t.Error("non-inheritable local should not be inherited")
+		}
+		traceID.Set("child")
+	})
+	wg.Wait()
+	if v, _ := traceID.Get(); v != "trace-1" {
+		t.Errorf("child should not affect parent, got %v", v)
+	}
+	userID.Remove()
+	if v := userID.GetOrDefault(-1); v != -1 {
+		t.Errorf("expected removed value, got %v", v)
+	}
+}
+
+// TestGoLocalWithPool 复用协程场景测试
+func TestGoLocalWithPool(t *testing.T) {
+	traceID := async.NewInheritableGoLocal[string]()
+	p := async.NewGoPool(&async.GoPoolConfig{Capacity: 1})
+	defer func() { _ = p.Shutdown(context.Background()) }()
+	results := make(chan string, 2)
+	for _, id := range []string{"a", "b"} {
+		traceID.Set(id)
+		_ = p.Go(async.WrapWithLocals(func() {
+			v, _ := traceID.Get()
+			results <- v
+		}))
+	}
+	async.ClearGoLocals()
+	if a, b := <-results, <-results; a != "a" || b != "b" {
+		t.Errorf("unexpected trace ids: %v %v", a, b)
+	}
+	_ = p.Go(func() {
+		if _, ok := traceID.Get(); ok {
+			t.Error("pool worker should be cleaned up")
+		}
+		results <- ""
+	})
+	<-results
+}
@@ -0,0 +1,109 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  task group 测试                                                                  //
+//  @author anonymous                                                               //
+//  @updated_at 2024.11.30 14:26:03                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestTaskGroupCancel 出错取消其他任务测试
+func TestTaskGroupCancel(t *testing.T) {
+	boom := errors.New("boom")
+	g, _ := async.NewTaskGroup(context.Background(), nil)
+	var cancelled int32
+	for i := 0; i < 4; i++ {
+		g.Go(func(ctx context.Context) error {
+			select {
+			case <-ctx.Done():
+				atomic.AddInt32(&cancelled, 1)
+				return nil
+			case <-time.After(time.Second):
+				return nil
+			}
+		})
+	}
+	g.Go(func(ctx context.Context) error { return boom })
+	if err := g.Wait(); !errors.Is(err, boom) {
+		t.Errorf("expected boom, got %v", err)
+	}
+	if atomic.LoadInt32(&cancelled) != 4 {
+		t.Errorf("expected siblings to be cancelled, got %d", cancelled)
+	}
+}
+
+// TestTaskGroupCollect 收集全部错误及 panic 测试
+func TestTaskGroupCollect(t *testing.T) {
+	e1, e2 := errors.New("e1"), errors.New("e2")
+	g, _ := async.NewTaskGroup(context.Background(), &async.TaskGroupConfig{ContinueOnError: true})
+	g.Go(func(ctx context.Context) error { return e1 })
+	g.Go(func(ctx context.Context) error { return e2 })
+	g.Go(func(ctx context.Context) error { panic("boom") })
+	err := g.Wait()
+	var pe *async.PanicError
+	if !errors.Is(err, e1) || !errors.Is(err, e2) || !errors.As(err, &pe) || len(pe.Stack) == 0 {
+		t.Errorf("expected all errors, got %v", err)
+	}
+}
+
+// TestTaskGroupLimit 并发限制及协程回收测试
+func TestTaskGroupLimit(t *testing.T) {
+	before := runtime.NumGoroutine()
+	g, _ := async.NewTaskGroup(context.Background(), &async.TaskGroupConfig{Limit: 2})
+	var running, peak int32
+	for i := 0; i < 10; i++ {
+		g.Go(func(ctx context.Context) error {
+			cur := atomic.AddInt32(&running, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if cur <= old || atomic.CompareAndSwapInt32(&peak, old, cur) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if peak > 2 {
+		t.Errorf("expected at most 2 concurrent tasks, got %d", peak)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("goroutines leaked: before=%d after=%d", before, after)
+	}
+	// 父上下文取消后不再启动任务
+	ctx, cancel := context.WithCancel(context.Background())
+	g, _ = async.NewTaskGroup(ctx, &async.TaskGroupConfig{Limit: 1})
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	cancel()
+	g.Go(func(ctx context.Context) error {
+		t.Error("task should not start after cancellation")
+		return nil
+	})
+	if err := g.Wait(); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context canceled, got %v", err)
+	}
+}
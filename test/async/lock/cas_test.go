@@ -1,7 +1,12 @@
 package lock
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async/lock"
 )
 
 // ================================================================================ //
@@ -21,7 +26,24 @@ import (
 
 // TestCASSignal CAS 信号量单元测试
 func TestCASSignal(t *testing.T) {
-
+	s := &lock.CASSignal{}
+	s.Add(3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			time.Sleep(5 * time.Millisecond)
+			s.Done()
+		}()
+	}
+	s.Wait()
+	if s.Status() != 0 {
+		t.Errorf("expected 0, got %d", s.Status())
+	}
+	s.Increase()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := s.WaitContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
 }
 
 // TestCASSwitch CAS 原子开关单元测试
@@ -0,0 +1,137 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async/lock"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  锁调试模式 测试                                                                   //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.06 16:40:18                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// collectEvents 收集调试事件
+func collectEvents() (func(*lock.DebugEvent), func(kind lock.DebugEventKind) []*lock.DebugEvent) {
+	var mu sync.Mutex
+	var events []*lock.DebugEvent
+	hook := func(e *lock.DebugEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, e)
+	}
+	get := func(kind lock.DebugEventKind) []*lock.DebugEvent {
+		mu.Lock()
+		defer mu.Unlock()
+		var res []*lock.DebugEvent
+		for _, e := range events {
+			if e.Kind == kind {
+				res = append(res, e)
+			}
+		}
+		return res
+	}
+	return hook, get
+}
+
+// TestDebugDeadlock 等待图成环检测测试
+func TestDebugDeadlock(t *testing.T) {
+	hook, events := collectEvents()
+	lock.EnableDebug(&lock.DebugConfig{Hook: hook, CaptureStack: true})
+	defer lock.DisableDebug()
+
+	a, b := &lock.CustomLock{}, &lock.CustomLock{}
+	a.SetName("A")
+	b.SetName("B")
+	barrier := lock.NewBarrier(2, nil)
+	var wg sync.WaitGroup
+	cross := func(first, second *lock.CustomLock) {
+		defer wg.Done()
+		first.Lock()
+		defer first.Unlock()
+		_, _ = barrier.Await(context.Background())
+		ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancel()
+		if err := second.LockContext(ctx); err == nil {
+			second.Unlock()
+		}
+	}
+	wg.Add(2)
+	go cross(a, b)
+	go cross(b, a)
+	wg.Wait()
+
+	deadlocks := events(lock.DebugDeadlock)
+	if len(deadlocks) == 0 {
+		t.Fatal("expected deadlock event")
+	}
+	cycle := deadlocks[0].Cycle
+	if len(cycle) != 2 || cycle[0].Owner != cycle[1].Goroutine || cycle[1].Owner != cycle[0].Goroutine {
+		t.Fatalf("unexpected cycle %+v", cycle)
+	}
+	if s := deadlocks[0].String(); !strings.Contains(s, "waits A") || !strings.Contains(s, "waits B") {
+		t.Fatalf("unexpected event description %q", s)
+	}
+	// 超时放弃后等待图不再成环
+	if report := lock.DebugReportNow(); len(report.Deadlocks) != 0 {
+		t.Fatalf("unexpected deadlocks after timeout: %+v", report.Deadlocks)
+	}
+}
+
+// TestDebugLongHold 长时间持有与报告测试
+func TestDebugLongHold(t *testing.T) {
+	hook, events := collectEvents()
+	lock.EnableDebug(&lock.DebugConfig{LongHoldThreshold: 20 * time.Millisecond, Hook: hook})
+	defer lock.DisableDebug()
+
+	l := &lock.CustomLock{}
+	l.SetName("slow")
+	l.Lock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Lock()
+		l.Unlock()
+	}()
+	time.Sleep(60 * time.Millisecond)
+
+	report := lock.DebugReportNow()
+	if len(report.Locks) != 1 || report.Locks[0].Owner == 0 || len(report.Locks[0].Waiters) != 1 {
+		t.Fatalf("unexpected report %+v", report.Locks)
+	}
+	text := report.String()
+	if !strings.Contains(text, "lock slow") || !strings.Contains(text, "waited by goroutine") {
+		t.Fatalf("unexpected text report:\n%s", text)
+	}
+	l.Unlock()
+	<-done
+
+	if n := len(events(lock.DebugLongHold)); n != 1 {
+		t.Fatalf("expected 1 long hold event, got %d", n)
+	}
+	data, err := lock.DebugReportNow().JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded lock.DebugReport
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	info := decoded.Locks[0]
+	if info.Owner != 0 || info.Acquisitions != 2 || info.Contentions != 1 || info.MaxHold < 50*time.Millisecond {
+		t.Fatalf("unexpected lock info %+v", info)
+	}
+}
@@ -1,6 +1,8 @@
 package lock
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/Anonymouscn/go-partner/async/lock"
 	"runtime"
@@ -10,6 +12,7 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+	"unsafe"
 )
 
 // ================================================================================ //
@@ -94,6 +97,117 @@ func TestCustomLock(t *testing.T) {
 	}
 }
 
+// TestCustomLockPadding 锁结构体填充至缓存行整数倍测试
+func TestCustomLockPadding(t *testing.T) {
+	if size := unsafe.Sizeof(lock.CustomLock{}); size%64 != 0 {
+		t.Errorf("expected CustomLock size to be a multiple of 64, got %d", size)
+	}
+}
+
+// TestCustomLockFair 公平模式测试 (按到达顺序获取锁)
+func TestCustomLockFair(t *testing.T) {
+	l := lock.NewFairLock()
+	l.Lock()
+	order := make(chan int, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			l.Lock()
+			order <- id
+			l.Unlock()
+		}(i)
+		// 保证等待者按顺序入队
+		time.Sleep(5 * time.Millisecond)
+	}
+	l.Unlock()
+	wg.Wait()
+	close(order)
+	expected := 0
+	for id := range order {
+		if id != expected {
+			t.Errorf("expected goroutine %d, got %d", expected, id)
+		}
+		expected++
+	}
+}
+
+// TestCustomLockContext 上下文取消测试
+func TestCustomLockContext(t *testing.T) {
+	for _, fair := range []bool{false, true} {
+		l := &lock.CustomLock{}
+		l.SetFair(fair)
+		l.Lock()
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- l.LockContext(ctx)
+		}()
+		if err := <-errCh; !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("fair=%v: expected deadline exceeded, got %v", fair, err)
+		}
+		cancel()
+		// 放弃等待后锁仍可正常移交
+		done := make(chan struct{})
+		go func() {
+			if err := l.LockContext(context.Background()); err != nil {
+				t.Errorf("fair=%v: unexpected error %v", fair, err)
+			}
+			l.Unlock()
+			close(done)
+		}()
+		time.Sleep(5 * time.Millisecond)
+		l.Unlock()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatalf("fair=%v: waiter not woken after unlock", fair)
+		}
+	}
+}
+
+// TestCustomLockStarvation 饥饿模式测试 (长时间持有锁时等待者不会被新来者持续插队)
+func TestCustomLockStarvation(t *testing.T) {
+	l := &lock.CustomLock{}
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	// 持续抢占锁的协程
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				l.Lock()
+				time.Sleep(100 * time.Microsecond)
+				l.Unlock()
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	acquired := make(chan struct{})
+	go func() {
+		l.Lock()
+		l.Unlock()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Error("waiter starved")
+	}
+	close(stop)
+	wg.Wait()
+	if l.IsLocked() {
+		t.Error("expected lock released")
+	}
+}
+
 // 模拟临界区的工作负载
 func doSomething() {
 	// 模拟一个很小的工作量
@@ -175,3 +289,42 @@ func BenchmarkLongCriticalSection(b *testing.B) {
 		})
 	})
 }
+
+// BenchmarkContention 高竞争场景下 CustomLock (普通/公平模式) 与 sync.Mutex 对比
+func BenchmarkContention(b *testing.B) {
+	for _, parallelism := range []int{1, 8, 64} {
+		b.Run("CustomLock-"+strconv.Itoa(parallelism), func(b *testing.B) {
+			l := &lock.CustomLock{}
+			b.SetParallelism(parallelism)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					l.Lock()
+					doSomething()
+					l.Unlock()
+				}
+			})
+		})
+		b.Run("FairLock-"+strconv.Itoa(parallelism), func(b *testing.B) {
+			l := lock.NewFairLock()
+			b.SetParallelism(parallelism)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					l.Lock()
+					doSomething()
+					l.Unlock()
+				}
+			})
+		})
+		b.Run("Mutex-"+strconv.Itoa(parallelism), func(b *testing.B) {
+			l := &sync.Mutex{}
+			b.SetParallelism(parallelism)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					l.Lock()
+					doSomething()
+					l.Unlock()
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async/lock"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  locker 测试                                                                      //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.05 11:26:40                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestLocker 租约锁各后端一致性测试
+func TestLocker(t *testing.T) {
+	dir := t.TempDir()
+	redis := newMemoryRedis()
+	backends := map[string]func(owner string) lock.Locker{
+		"local": func() func(string) lock.Locker {
+			l := lock.NewLocalLocker(nil)
+			return func(string) lock.Locker { return l }
+		}(),
+		"file": func(owner string) lock.Locker {
+			l, err := lock.NewFileLocker(dir, &lock.LockerConfig{Owner: owner, RetryInterval: 5 * time.Millisecond})
+			if err != nil {
+				t.Fatal(err)
+			}
+			return l
+		},
+		"redis": func(owner string) lock.Locker {
+			return lock.NewRedisLocker(redis, "", &lock.LockerConfig{Owner: owner, RetryInterval: 5 * time.Millisecond})
+		},
+	}
+	for name, newLocker := range backends {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			a, b := newLocker("a"), newLocker("b")
+			key := "orders/" + name
+			first, err := a.TryAcquire(ctx, key, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err = b.TryAcquire(ctx, key, time.Second); !errors.Is(err, lock.ErrLockHeld) {
+				t.Errorf("expected ErrLockHeld, got %v", err)
+			}
+			if err = a.Renew(ctx, first, time.Second); err != nil {
+				t.Errorf("renew: %v", err)
+			}
+			// 释放后等待者获取锁, token 递增
+			go func() {
+				time.Sleep(20 * time.Millisecond)
+				_ = a.Release(ctx, first)
+			}()
+			second, err := b.Acquire(ctx, key, 50*time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if second.Token <= first.Token {
+				t.Errorf("expected increasing fencing token, got %d then %d", first.Token, second.Token)
+			}
+			if err = a.Release(ctx, first); !errors.Is(err, lock.ErrLeaseLost) {
+				t.Errorf("expected ErrLeaseLost for stale lease, got %v", err)
+			}
+			// 租约过期后可被其他持有者获取, 原租约失效
+			third, err := a.Acquire(ctx, key, time.Second)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = b.Renew(ctx, second, time.Second); !errors.Is(err, lock.ErrLeaseLost) {
+				t.Errorf("expected ErrLeaseLost after expiry, got %v", err)
+			}
+			timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			if _, err = b.Acquire(timeout, key, time.Second); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected deadline exceeded, got %v", err)
+			}
+			if err = a.Release(ctx, third); err != nil {
+				t.Errorf("release: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async/lock"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  同步原语 测试                                                                     //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.06 14:12:05                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestSemaphore 加权信号量测试
+func TestSemaphore(t *testing.T) {
+	s := lock.NewSemaphore(4)
+	ctx := context.Background()
+	if err := s.Acquire(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	if s.TryAcquire(2) {
+		t.Error("expected try acquire to fail")
+	}
+	if err := s.Acquire(ctx, 5); !errors.Is(err, lock.ErrSemaphoreWeight) {
+		t.Errorf("expected ErrSemaphoreWeight, got %v", err)
+	}
+	// 大权重等待者在前时, 小权重申请按顺序排队
+	order := make(chan int64, 2)
+	go func() {
+		_ = s.Acquire(ctx, 4)
+		order <- 4
+		s.Release(4)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	go func() {
+		_ = s.Acquire(ctx, 1)
+		order <- 1
+		s.Release(1)
+	}()
+	time.Sleep(5 * time.Millisecond)
+	s.Release(3)
+	if first, second := <-order, <-order; first != 4 || second != 1 {
+		t.Errorf("expected FIFO order 4,1, got %d,%d", first, second)
+	}
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	_ = s.Acquire(ctx, 4)
+	if err := s.Acquire(timeout, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	s.Release(4)
+	if s.Available() != 4 {
+		t.Errorf("expected 4 available, got %d", s.Available())
+	}
+}
+
+// TestLatch 倒计时门闩测试
+func TestLatch(t *testing.T) {
+	l := lock.NewLatch(3)
+	for i := 0; i < 3; i++ {
+		go l.CountDown()
+	}
+	if err := l.Wait(context.Background()); err != nil || l.Count() != 0 {
+		t.Errorf("unexpected latch state: %v %d", err, l.Count())
+	}
+}
+
+// TestBarrier 循环屏障测试
+func TestBarrier(t *testing.T) {
+	var trips int64
+	b := lock.NewBarrier(3, func() { atomic.AddInt64(&trips, 1) })
+	var wg sync.WaitGroup
+	for round := 0; round < 2; round++ {
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := b.Await(context.Background()); err != nil {
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+	if trips != 2 {
+		t.Errorf("expected 2 trips, got %d", trips)
+	}
+	// 等待者取消后屏障损坏
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := b.Await(context.Background())
+		errCh <- err
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	for b.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := b.Await(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+	if err := <-errCh; !errors.Is(err, lock.ErrBarrierBroken) {
+		t.Errorf("expected ErrBarrierBroken, got %v", err)
+	}
+	b.Reset()
+	if b.IsBroken() {
+		t.Error("expected barrier reset")
+	}
+}
+
+// TestBarrierActionPanic 放行动作 panic 及在动作中访问屏障测试
+func TestBarrierActionPanic(t *testing.T) {
+	var b *lock.Barrier
+	b = lock.NewBarrier(2, func() {
+		// 动作在锁外执行, 可访问屏障状态
+		_ = b.Waiting()
+		panic("action failed")
+	})
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := b.Await(context.Background())
+		errCh <- err
+	}()
+	for b.Waiting() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r != "action failed" {
+				t.Errorf("expected action panic, got %v", r)
+			}
+		}()
+		_, _ = b.Await(context.Background())
+	}()
+	if err := <-errCh; !errors.Is(err, lock.ErrBarrierBroken) {
+		t.Errorf("expected ErrBarrierBroken, got %v", err)
+	}
+	if !b.IsBroken() {
+		t.Error("expected barrier broken after action panic")
+	}
+}
+
+// TestEvent 广播事件测试
+func TestEvent(t *testing.T) {
+	var e lock.Event
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := e.Wait(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	time.Sleep(5 * time.Millisecond)
+	e.Set()
+	wg.Wait()
+	e.Clear()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := e.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded after clear, got %v", err)
+	}
+}
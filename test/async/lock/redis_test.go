@@ -0,0 +1,103 @@
+package lock
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async/lock"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  locker 内存 Redis 替身                                                            //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.05 11:26:40                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestRedisLockerKeys Redis 锁 key 使用 hash tag 测试
+func TestRedisLockerKeys(t *testing.T) {
+	redis := newMemoryRedis()
+	locker := lock.NewRedisLocker(redis, "", nil)
+	if _, err := locker.TryAcquire(context.Background(), "orders", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"lock:{orders}", "lock:{orders}:token"} {
+		if _, ok := redis.data[key]; !ok {
+			t.Errorf("expected key %s, got %v", key, redis.data)
+		}
+	}
+}
+
+// memoryValue 内存 Redis 值
+type memoryValue struct {
+	value    string    // 值
+	expireAt time.Time // 过期时间 (零值表示不过期)
+}
+
+// memoryRedis 内存 Redis 替身 (仅实现 RedisLocker 使用的脚本语义)
+type memoryRedis struct {
+	lock sync.Mutex
+	data map[string]*memoryValue
+}
+
+// newMemoryRedis 新建内存 Redis 替身
+func newMemoryRedis() *memoryRedis {
+	return &memoryRedis{data: make(map[string]*memoryValue)}
+}
+
+// Eval 执行脚本 (仅支持获取, 续约及释放脚本)
+func (r *memoryRedis) Eval(_ context.Context, script string, keys []string, args ...any) (any, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	switch script {
+	case lock.RedisAcquireScript:
+		if _, ok := r.get(keys[0]); ok {
+			return int64(0), nil
+		}
+		counter, _ := r.get(keys[1])
+		token, _ := strconv.ParseInt(counter, 10, 64)
+		token++
+		r.data[keys[1]] = &memoryValue{value: strconv.FormatInt(token, 10)}
+		r.data[keys[0]] = &memoryValue{
+			value:    fmt.Sprintf("%v:%d", args[0], token),
+			expireAt: time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond),
+		}
+		return token, nil
+	case lock.RedisRenewScript:
+		if v, ok := r.get(keys[0]); !ok || v != args[0] {
+			return int64(0), nil
+		}
+		r.data[keys[0]].expireAt = time.Now().Add(time.Duration(args[1].(int64)) * time.Millisecond)
+		return int64(1), nil
+	case lock.RedisReleaseScript:
+		if v, ok := r.get(keys[0]); !ok || v != args[0] {
+			return int64(0), nil
+		}
+		delete(r.data, keys[0])
+		return int64(1), nil
+	}
+	return nil, fmt.Errorf("unsupported script for memory redis")
+}
+
+// get 获取未过期的值 (需持有锁)
+func (r *memoryRedis) get(key string) (string, bool) {
+	v, ok := r.data[key]
+	if !ok {
+		return "", false
+	}
+	if !v.expireAt.IsZero() && !time.Now().Before(v.expireAt) {
+		delete(r.data, key)
+		return "", false
+	}
+	return v.value, true
+}
@@ -0,0 +1,131 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async/lock"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  reentrant rwlock 测试                                                            //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.04 16:08:33                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//  2. bench test:                                                                  //
+//     $ go test -benchmem -run=^$ -bench ^<$function_name>$ -count=<$count> -v     //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestReentrantRWLock 可重入读写锁测试
+func TestReentrantRWLock(t *testing.T) {
+	l := &lock.ReentrantRWLock{}
+	l.Lock()
+	l.Lock()
+	l.RLock()
+	if n := l.HoldCount(); n != 2 {
+		t.Errorf("expected hold count 2, got %d", n)
+	}
+	l.RUnlock()
+	l.Unlock()
+	if !l.IsLocked() {
+		t.Error("expected lock held after partial unlock")
+	}
+	l.Unlock()
+	if l.IsLocked() {
+		t.Error("expected lock released")
+	}
+	// 读锁升级返回错误
+	l.RLock()
+	l.RLock()
+	if err := l.TryLock(context.Background()); !errors.Is(err, lock.ErrLockUpgrade) {
+		t.Errorf("expected ErrLockUpgrade, got %v", err)
+	}
+	if n := l.ReadHoldCount(); n != 2 {
+		t.Errorf("expected read hold count 2, got %d", n)
+	}
+	l.RUnlock()
+	l.RUnlock()
+	if l.Readers() != 0 {
+		t.Error("expected no readers")
+	}
+}
+
+// TestReentrantRWLockWriterPreference 写锁优先及超时测试
+func TestReentrantRWLockWriterPreference(t *testing.T) {
+	l := &lock.ReentrantRWLock{}
+	l.RLock()
+	writerDone := make(chan struct{})
+	go func() {
+		l.Lock()
+		close(writerDone)
+		l.Unlock()
+	}()
+	time.Sleep(20 * time.Millisecond)
+	// 写者等待中, 新读者阻塞
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	readErr := make(chan error, 1)
+	go func() {
+		err := l.TryRLock(ctx)
+		if err == nil {
+			l.RUnlock()
+		}
+		readErr <- err
+	}()
+	if err := <-readErr; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected reader blocked by waiting writer, got %v", err)
+	}
+	// 已持有读锁的协程可重入
+	l.RLock()
+	l.RUnlock()
+	l.RUnlock()
+	<-writerDone
+}
+
+// TestReentrantRWLockExclusion 互斥测试
+func TestReentrantRWLockExclusion(t *testing.T) {
+	l := &lock.ReentrantRWLock{}
+	var (
+		wg      sync.WaitGroup
+		counter int
+		writing bool
+	)
+	for i := 0; i < 16; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 100; k++ {
+				l.Lock()
+				l.Lock()
+				writing = true
+				counter++
+				writing = false
+				l.Unlock()
+				l.Unlock()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for k := 0; k < 100; k++ {
+				l.RLock()
+				if writing {
+					t.Error("reader observed writer in critical section")
+				}
+				l.RUnlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if counter != 1600 {
+		t.Errorf("expected 1600, got %d", counter)
+	}
+}
@@ -14,3 +14,101 @@ package async
 //                                                                                  //
 //                                                                                  //
 // ================================================================================ //
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// TestPromiseThen 顺序执行测试 (上一步结果传递给下一步)
+func TestPromiseThen(t *testing.T) {
+	res, err := async.NewPromise().
+		Then(async.Action{Fn: func() int { return 1 }}).
+		Then(async.Action{Fn: func(x, y int) (int, error) { return x + y, nil }, Args: []any{2}}).
+		Then(async.Action{Fn: func(x int) string { return strconv.Itoa(x * 10) }}).
+		Get()
+	if err != nil || len(res) != 1 || res[0] != "30" {
+		t.Errorf("unexpected result: %v %v", res, err)
+	}
+}
+
+// TestPromiseParallel 并行执行测试
+func TestPromiseParallel(t *testing.T) {
+	start := time.Now()
+	sleep := func(x int) int {
+		time.Sleep(50 * time.Millisecond)
+		return x
+	}
+	res, err := async.NewPromise().
+		Then(
+			async.Action{Fn: sleep, Args: []any{1}},
+			async.Action{Fn: sleep, Args: []any{2}},
+			async.Action{Fn: sleep, Args: []any{3}},
+		).
+		Then(async.Action{Fn: func(a, b, c int) int { return a*100 + b*10 + c }}).
+		Get()
+	if err != nil || len(res) != 1 || res[0] != 123 {
+		t.Errorf("unexpected result: %v %v", res, err)
+	}
+	if cost := time.Since(start); cost > 140*time.Millisecond {
+		t.Errorf("parallel stage took too long: %v", cost)
+	}
+}
+
+// TestPromiseCatch 错误捕获测试 (含 panic)
+func TestPromiseCatch(t *testing.T) {
+	var skipped, finally int32
+	boom := errors.New("boom")
+	p := async.NewPromise().
+		Then(async.Action{Fn: func() (int, error) { return 0, boom }}).
+		Then(async.Action{Fn: func() { atomic.AddInt32(&skipped, 1) }}).
+		Catch(func(err error) []any {
+			if !errors.Is(err, boom) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			return []any{42}
+		}).
+		Then(async.Action{Fn: func(x int) int { panic("bad " + strconv.Itoa(x)) }}).
+		Finally(func() { atomic.AddInt32(&finally, 1) })
+	res, err := p.Get()
+	var pe *async.PanicError
+	if res != nil || !errors.As(err, &pe) || pe.Value != "bad 42" {
+		t.Errorf("unexpected result: %v %v", res, err)
+	}
+	if atomic.LoadInt32(&skipped) != 0 || atomic.LoadInt32(&finally) != 1 {
+		t.Errorf("unexpected stage execution: skipped=%d finally=%d", skipped, finally)
+	}
+	if errs := p.Errors(); len(errs) != 2 {
+		t.Errorf("expected 2 errors, got %v", errs)
+	}
+}
+
+// TestPromiseFinallyPanic 最终处理 panic 测试
+func TestPromiseFinallyPanic(t *testing.T) {
+	p := async.Resolve(1).
+		Finally(func() { panic("finally failed") }).
+		Then(async.Action{Fn: func() { t.Error("then after failed finally should be skipped") }})
+	_, err := p.Get()
+	var pe *async.PanicError
+	if !errors.As(err, &pe) || pe.Value != "finally failed" {
+		t.Errorf("expected PanicError, got %v", err)
+	}
+}
+
+// TestPromiseApplyAfterThen 执行中应用配置测试 (-race 下无数据竞争)
+func TestPromiseApplyAfterThen(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := async.NewPromise().Then(async.Action{Fn: func() { time.Sleep(20 * time.Millisecond) }})
+	time.Sleep(5 * time.Millisecond)
+	p.Apply(&async.PromiseConfig{Context: ctx})
+	if _, err := p.Get(); err != nil && !errors.Is(err, context.Canceled) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
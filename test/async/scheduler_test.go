@@ -0,0 +1,195 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  scheduler 测试                                                                   //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.02 15:21:46                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestParseCron cron 表达式测试
+func TestParseCron(t *testing.T) {
+	cases := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, 1, 1, 10, 7, 30, 0, time.UTC), time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)},
+		{"0 30 9 * * MON-FRI", time.Date(2024, 11, 30, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 2, 9, 30, 0, 0, time.UTC)},
+		{"CRON_TZ=Asia/Shanghai 0 9 * * *", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)},
+		{"0 0 13 * FRI", time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 9, 6, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		s, err := async.ParseCronInLocation(c.expr, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", c.expr, err)
+		}
+		if got := s.Next(c.from); !got.Equal(c.want) {
+			t.Errorf("%s: expected %v, got %v", c.expr, c.want, got)
+		}
+	}
+	for _, expr := range []string{"* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "TZ=Nowhere/City * * * * *"} {
+		if _, err := async.ParseCron(expr); !errors.Is(err, async.ErrInvalidCron) {
+			t.Errorf("%s: expected ErrInvalidCron, got %v", expr, err)
+		}
+	}
+}
+
+// TestSchedulerFixedRate 固定频率及重叠策略测试
+func TestSchedulerFixedRate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := async.NewFakeClock(start)
+	s := async.NewScheduler(&async.SchedulerConfig{Clock: clock})
+	started := make(chan struct{}, 8)
+	release := make(chan struct{})
+	job, err := s.Every(time.Minute, func(ctx context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}, &async.JobConfig{Overlap: async.OverlapSkip})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	<-started
+	// 上一次执行未结束, 本次跳过
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	clock.BlockUntil(1)
+	if st := job.Stats(); st.Skipped != 1 || st.Running != 1 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+	if next := job.NextRun(); !next.Equal(start.Add(3 * time.Minute)) {
+		t.Errorf("unexpected next run: %v", next)
+	}
+	close(release)
+	job.Cancel()
+	<-job.Done()
+	_ = s.Shutdown(context.Background())
+	if st := job.Stats(); st.Runs != 1 {
+		t.Errorf("expected 1 run, got %+v", st)
+	}
+}
+
+// TestSchedulerMisfire 错过执行策略测试
+func TestSchedulerMisfire(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for policy, want := range map[async.MisfirePolicy]int64{
+		async.MisfireSkip:    0,
+		async.MisfireRunOnce: 1,
+		async.MisfireRunAll:  3,
+	} {
+		clock := async.NewFakeClock(start)
+		s := async.NewScheduler(&async.SchedulerConfig{Clock: clock})
+		var runs int64
+		job, _ := s.Every(time.Minute, func(ctx context.Context) error {
+			atomic.AddInt64(&runs, 1)
+			return nil
+		}, &async.JobConfig{Misfire: policy, Overlap: async.OverlapParallel})
+		clock.BlockUntil(1)
+		clock.Advance(3*time.Minute + 10*time.Second)
+		clock.BlockUntil(1)
+		if next := job.NextRun(); !next.Equal(start.Add(4 * time.Minute)) {
+			t.Errorf("policy %v: unexpected next run %v", policy, next)
+		}
+		_ = s.Shutdown(context.Background())
+		if got := atomic.LoadInt64(&runs); got != want {
+			t.Errorf("policy %v: expected %d runs, got %d", policy, want, got)
+		}
+		if st := job.Stats(); st.Misfires != 1 {
+			t.Errorf("policy %v: expected 1 misfire, got %+v", policy, st)
+		}
+	}
+}
+
+// TestSchedulerMisfireRunAllSkip 默认重叠策略下逐次补执行测试 (串行执行, 不跳过)
+func TestSchedulerMisfireRunAllSkip(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := async.NewFakeClock(start)
+	s := async.NewScheduler(&async.SchedulerConfig{Clock: clock})
+	var runs, running, overlapped int64
+	job, _ := s.Every(time.Minute, func(ctx context.Context) error {
+		if atomic.AddInt64(&running, 1) > 1 {
+			atomic.AddInt64(&overlapped, 1)
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt64(&running, -1)
+		atomic.AddInt64(&runs, 1)
+		return nil
+	}, &async.JobConfig{Misfire: async.MisfireRunAll})
+	clock.BlockUntil(1)
+	clock.Advance(3*time.Minute + 10*time.Second)
+	deadline := time.Now().Add(2 * time.Second)
+	for atomic.LoadInt64(&runs) < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	_ = s.Shutdown(context.Background())
+	if got := atomic.LoadInt64(&runs); got != 3 || overlapped != 0 {
+		t.Errorf("expected 3 serial runs, got %d runs / %d overlapped", got, overlapped)
+	}
+	if st := job.Stats(); st.Skipped != 0 {
+		t.Errorf("expected no skipped runs, got %+v", st)
+	}
+}
+
+// TestSchedulerOnce 单次及固定延迟任务测试
+func TestSchedulerOnce(t *testing.T) {
+	clock := async.NewFakeClock(time.Now())
+	var failed int64
+	s := async.NewScheduler(&async.SchedulerConfig{
+		Clock: clock,
+		ErrorHandler: func(job *async.Job, err error) {
+			atomic.AddInt64(&failed, 1)
+		},
+	})
+	result := make(chan error, 1)
+	once, _ := s.After(time.Second, func(ctx context.Context) error {
+		// 等待调度循环先行退出
+		time.Sleep(10 * time.Millisecond)
+		result <- ctx.Err()
+		return errors.New("boom")
+	}, nil)
+	var delayed int64
+	_, _ = s.EveryDelay(time.Second, func(ctx context.Context) error {
+		atomic.AddInt64(&delayed, 1)
+		return nil
+	}, nil)
+	clock.BlockUntil(2)
+	clock.Advance(time.Second)
+	<-once.Done()
+	// 调度结束后执行中任务的上下文不应被取消
+	if err := <-result; err != nil {
+		t.Errorf("one-shot job context cancelled: %v", err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	clock.BlockUntil(1)
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if atomic.LoadInt64(&delayed) != 2 || atomic.LoadInt64(&failed) != 1 {
+		t.Errorf("unexpected runs: delayed=%d failed=%d", delayed, failed)
+	}
+	if _, err := s.After(time.Second, func(ctx context.Context) error { return nil }, nil); !errors.Is(err, async.ErrSchedulerClosed) {
+		t.Errorf("expected ErrSchedulerClosed, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package env
 
 import (
 	"bufio"
+	"context"
 	"encoding/csv"
 	"fmt"
 	"github.com/Anonymouscn/go-partner/flow"
@@ -73,7 +74,7 @@ func TestReadSQLReport(t *testing.T) {
 	}
 
 	// 等待处理结果
-	f.Stop()
+	f.Stop(context.Background())
 
 	// 统计计数
 	sum, kind, sc, ic, uc, dc := int64(0), int64(0), int64(0), int64(0), int64(0), int64(0)
@@ -0,0 +1,200 @@
+package flow
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/flow"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  flow 数据分发器 测试                                                               //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.07 10:26:41                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// collector 输出流收集器
+type collector struct {
+	lock  sync.Mutex
+	items map[string][]int // map[Flow ID]数据
+}
+
+// attach 注入输出流消费者
+func (c *collector) attach(f *flow.DataFlow[int]) *flow.DataFlow[int] {
+	return f.Consume(func(dc <-chan int, ec chan<- error, args ...any) {
+		for v := range dc {
+			c.lock.Lock()
+			c.items[f.ID] = append(c.items[f.ID], v)
+			c.lock.Unlock()
+		}
+	})
+}
+
+// produceRange 注入生产 [0, n) 的生产者
+func produceRange(f *flow.DataFlow[int], n int) {
+	f.Produce(func(dc chan<- int, ec chan<- error, args ...any) {
+		for i := 0; i < n; i++ {
+			dc <- i
+		}
+	})
+}
+
+// TestDispatcherStrategy 分发策略测试
+func TestDispatcherStrategy(t *testing.T) {
+	cases := []struct {
+		name     string
+		strategy int
+		total    int
+	}{
+		{"round robin", flow.RouteRoundRobin, 100},
+		{"broadcast", flow.RouteBroadcast, 300},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &collector{items: make(map[string][]int)}
+			outs := []*flow.DataFlow[int]{
+				c.attach(flow.NewDataFlow[int](4)),
+				c.attach(flow.NewDataFlow[int](4)),
+				c.attach(flow.NewDataFlow[int](4)),
+			}
+			in := flow.NewDataFlow[int](8).
+				CustomDispatcher(flow.NewDataDispatcher[int](&flow.DispatcherConfig[int]{Strategy: tc.strategy}))
+			for _, out := range outs {
+				if err := in.DataDispatcher.AddOutBound(out); err != nil {
+					t.Fatal(err)
+				}
+			}
+			in.Dispatch()
+			produceRange(in, 100)
+			in.Stop(context.Background())
+			for _, out := range outs {
+				out.Stop(context.Background())
+			}
+			total := 0
+			for _, out := range outs {
+				n := len(c.items[out.ID])
+				if tc.strategy == flow.RouteRoundRobin && n != 100/3 && n != 100/3+1 {
+					t.Fatalf("unbalanced round robin: %s got %d", out.ID, n)
+				}
+				total += n
+			}
+			if total != tc.total {
+				t.Fatalf("expected %d items, got %d", tc.total, total)
+			}
+		})
+	}
+}
+
+// TestDispatcherRoute 按 key 路由及运行时增删输出流测试
+func TestDispatcherRoute(t *testing.T) {
+	c := &collector{items: make(map[string][]int)}
+	even, odd, extra := c.attach(flow.NewDataFlow[int](4)), c.attach(flow.NewDataFlow[int](4)), c.attach(flow.NewDataFlow[int](4))
+	var dropped int64
+	d := flow.NewDataDispatcher[int](&flow.DispatcherConfig[int]{
+		Strategy:    flow.RouteHash,
+		KeyFn:       func(v int) string { return strconv.Itoa(v % 2) },
+		StrictRoute: true,
+		OnDrop:      func(int, string, error) { atomic.AddInt64(&dropped, 1) },
+	})
+	in := flow.NewDataFlow[int](8).CustomDispatcher(d)
+	for _, out := range []*flow.DataFlow[int]{even, odd, extra} {
+		if err := d.AddOutBound(out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.AddOutBound(even); err != flow.ErrOutboundExists {
+		t.Fatalf("expected ErrOutboundExists, got %v", err)
+	}
+	_ = d.AddRoute("0", even.ID)
+	_ = d.AddRoute("1", odd.ID)
+	in.Dispatch()
+	produceRange(in, 50)
+	// 移除 extra 输出流后其可正常关闭
+	if err := d.RemoveOutBound(extra.ID); err != nil {
+		t.Fatal(err)
+	}
+	extra.Stop(context.Background())
+	// 移除奇数路由后奇数数据被丢弃
+	time.Sleep(20 * time.Millisecond)
+	d.ClearRoute("1")
+	produceRange(in, 10)
+	in.Stop(context.Background())
+	even.Stop(context.Background())
+	odd.Stop(context.Background())
+	for _, v := range c.items[even.ID] {
+		if v%2 != 0 {
+			t.Fatalf("odd item %d routed to even flow", v)
+		}
+	}
+	for _, v := range c.items[odd.ID] {
+		if v%2 != 1 {
+			t.Fatalf("even item %d routed to odd flow", v)
+		}
+	}
+	if len(c.items[extra.ID]) != 0 {
+		t.Fatalf("unrouted items reached extra flow: %v", c.items[extra.ID])
+	}
+	if len(c.items[even.ID]) != 30 || len(c.items[odd.ID]) != 25 || dropped != 5 || d.Dropped() != 5 {
+		t.Fatalf("unexpected counts: even=%d odd=%d dropped=%d", len(c.items[even.ID]), len(c.items[odd.ID]), dropped)
+	}
+}
+
+// TestDispatcherBackpressure 输出流缓冲满时背压测试
+func TestDispatcherBackpressure(t *testing.T) {
+	release := make(chan struct{})
+	var received int64
+	out := flow.NewDataFlow[int](2).Consume(func(dc <-chan int, ec chan<- error, args ...any) {
+		<-release
+		for range dc {
+			atomic.AddInt64(&received, 1)
+		}
+	})
+	d := flow.NewDataDispatcher[int](&flow.DispatcherConfig[int]{BlockTimeout: -1})
+	_ = d.AddOutBound(out)
+	// 只接收输出流缓冲大小的数据, 其余丢弃 (并发分发同样精确)
+	var accepted int64
+	group := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		group.Add(1)
+		go func(i int) {
+			defer group.Done()
+			if d.Dispatch(i) == nil {
+				atomic.AddInt64(&accepted, 1)
+			}
+		}(i)
+	}
+	group.Wait()
+	if accepted != 2 || d.Dropped() != 8 {
+		t.Fatalf("expected 2 accepted / 8 dropped, got %d / %d", accepted, d.Dropped())
+	}
+	close(release)
+	d.Close()
+	out.Stop(context.Background())
+	if received != 2 {
+		t.Fatalf("expected 2 received, got %d", received)
+	}
+	if err := d.Dispatch(1); err != flow.ErrDispatcherClosed {
+		t.Fatalf("expected ErrDispatcherClosed, got %v", err)
+	}
+}
+
+// TestDispatcherConfig 哈希分发未配置 KeyFn 测试
+func TestDispatcherConfig(t *testing.T) {
+	defer func() {
+		if r := recover(); r != flow.ErrKeyFnRequired.Error() {
+			t.Fatalf("expected ErrKeyFnRequired panic, got %v", r)
+		}
+	}()
+	flow.NewDataDispatcher[int](&flow.DispatcherConfig[int]{Strategy: flow.RouteHash})
+}
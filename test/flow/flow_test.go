@@ -1,6 +1,7 @@
 package flow
 
 import (
+	"context"
 	"fmt"
 	"github.com/Anonymouscn/go-partner/flow"
 	"testing"
@@ -50,7 +51,7 @@ func TestDataFlow(t *testing.T) {
 		}
 	})
 	// 外部终止数据流
-	f.Stop()
+	f.Stop(context.Background())
 	fmt.Println("success !")
 	fmt.Println(m)
 }
@@ -85,7 +86,7 @@ func BenchmarkTestDataFlow(b *testing.B) {
 			}
 		})
 		// 外部终止数据流
-		f.Stop()
+		f.Stop(context.Background())
 		fmt.Println("success !")
 		fmt.Println(m)
 	}
@@ -0,0 +1,134 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/flow"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  flow 数据流生命周期 测试                                                           //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.09 10:42:16                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestFlowRunCancel 外部上下文取消时取消生产方并排空数据测试
+func TestFlowRunCancel(t *testing.T) {
+	var lock sync.Mutex
+	var events []string
+	record := func(event string) func() {
+		return func() {
+			lock.Lock()
+			defer lock.Unlock()
+			events = append(events, event)
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	// 手动开启: 开启前注册钩子及生产方
+	f := flow.NewDataFlowWithConfig[int](&flow.FlowConfig[int]{BufSize: 4, ManualStart: true}).
+		OnStart(record("start")).
+		OnDrained(record("drained")).
+		OnClosed(record("closed"))
+	var sent, received int64
+	f.ProduceContext(func(ctx context.Context, dc chan<- int, ec chan<- error) error {
+		for i := 0; ; i++ {
+			if err := flow.Send(ctx, dc, i); err != nil {
+				return err
+			}
+			atomic.AddInt64(&sent, 1)
+		}
+	})
+	f.Consume(func(dc <-chan int, ec chan<- error, args ...any) {
+		for range dc {
+			atomic.AddInt64(&received, 1)
+		}
+	})
+	time.Sleep(10 * time.Millisecond)
+	lock.Lock()
+	pending := len(events)
+	lock.Unlock()
+	if pending != 0 || atomic.LoadInt64(&sent) != 0 || f.Status() != flow.ClosedState {
+		t.Fatalf("expected flow not started before Run, events=%v sent=%d", events, sent)
+	}
+	f.Run(ctx)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	<-f.Done()
+	if sent == 0 || sent != received {
+		t.Fatalf("expected all sent items drained, sent=%d received=%d", sent, received)
+	}
+	if !reflect.DeepEqual(events, []string{"start", "drained", "closed"}) {
+		t.Fatalf("unexpected hook order %v", events)
+	}
+	if f.Status() != flow.ClosedState || f.Context().Err() == nil {
+		t.Fatal("expected closed flow with cancelled context")
+	}
+	// 关闭后注入的生产方被忽略, 钩子立即执行
+	f.Produce(func(dc chan<- int, ec chan<- error, args ...any) { dc <- 1 })
+	closed := false
+	f.OnClosed(func() { closed = true })
+	if !closed {
+		t.Fatal("expected OnClosed to run immediately after close")
+	}
+}
+
+// TestFlowStopDeadline 关闭超时时取消生产方测试
+func TestFlowStopDeadline(t *testing.T) {
+	f := flow.NewDataFlow[int](1)
+	errBoom := errors.New("boom")
+	produced := make(chan error, 1)
+	// 无消费方, 生产方阻塞在发送上
+	f.ProduceContext(func(ctx context.Context, dc chan<- int, ec chan<- error) error {
+		for i := 0; ; i++ {
+			if err := flow.Send(ctx, dc, i); err != nil {
+				produced <- err
+				return err
+			}
+		}
+	})
+	f.ProduceContext(func(ctx context.Context, dc chan<- int, ec chan<- error) error {
+		return errBoom
+	})
+	var errs []error
+	handled := make(chan struct{})
+	f.OnError(func(ec <-chan error, args ...any) {
+		defer close(handled)
+		for err := range ec {
+			errs = append(errs, err)
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := f.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("Stop did not respect deadline")
+	}
+	if err := <-produced; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected producer to observe cancellation, got %v", err)
+	}
+	// 关闭流程在生产方退出后于后台完成
+	if err := f.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	<-handled
+	// 取消导致的错误不写入错误管道
+	if len(errs) != 1 || !errors.Is(errs[0], errBoom) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+}
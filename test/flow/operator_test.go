@@ -0,0 +1,206 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/flow"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  flow 流式算子 测试                                                                //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.07 15:08:33                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// sink 收集输出流数据及错误
+func sink[T any](f *flow.DataFlow[T]) (items func() []T, errs func() []error) {
+	var res []T
+	var errList []error
+	var lock sync.Mutex
+	handled := make(chan struct{})
+	f.OnError(func(ec <-chan error, args ...any) {
+		defer close(handled)
+		for err := range ec {
+			lock.Lock()
+			errList = append(errList, err)
+			lock.Unlock()
+		}
+	})
+	f.Consume(func(dc <-chan T, ec chan<- error, args ...any) {
+		for v := range dc {
+			lock.Lock()
+			res = append(res, v)
+			lock.Unlock()
+		}
+	})
+	items = func() []T {
+		lock.Lock()
+		defer lock.Unlock()
+		return res
+	}
+	errs = func() []error {
+		<-handled
+		lock.Lock()
+		defer lock.Unlock()
+		return errList
+	}
+	return
+}
+
+// source 生产 [0, n) 并关闭的输入流
+func source(n int) *flow.DataFlow[int] {
+	f := flow.NewDataFlow[int](8)
+	f.Produce(func(dc chan<- int, ec chan<- error, args ...any) {
+		for i := 0; i < n; i++ {
+			dc <- i
+		}
+	})
+	return f
+}
+
+// TestOperatorPipeline 算子组合及错误转发测试
+func TestOperatorPipeline(t *testing.T) {
+	src := source(20)
+	errOdd := errors.New("odd")
+	mapped := flow.Map(src, func(v int) (string, error) {
+		if v%2 == 1 {
+			return "", errOdd
+		}
+		return strconv.Itoa(v), nil
+	})
+	filtered := flow.Filter(mapped, func(s string) bool { return s != "0" })
+	expanded := flow.FlatMap(filtered, func(s string) ([]string, error) { return []string{s, s}, nil })
+	distinct := flow.Distinct(expanded, func(s string) string { return s })
+	batched := flow.Batch(distinct, 4, 0)
+	items, errs := sink(batched)
+
+	src.Stop(context.Background())
+	expected := [][]string{{"2", "4", "6", "8"}, {"10", "12", "14", "16"}, {"18"}}
+	if !reflect.DeepEqual(items(), expected) {
+		t.Fatalf("expected %v, got %v", expected, items())
+	}
+	if len(errs()) != 10 || !errors.Is(errs()[0], errOdd) {
+		t.Fatalf("expected 10 forwarded errors, got %v", errs())
+	}
+}
+
+// TestOperatorBatchMaxWait 分批超时输出测试
+func TestOperatorBatchMaxWait(t *testing.T) {
+	src := flow.NewDataFlow[int](8)
+	batched := flow.Batch(src, 100, 20*time.Millisecond)
+	items, _ := sink(batched)
+	src.Produce(func(dc chan<- int, ec chan<- error, args ...any) {
+		dc <- 1
+		dc <- 2
+		time.Sleep(60 * time.Millisecond)
+		dc <- 3
+	})
+	time.Sleep(30 * time.Millisecond)
+	src.Stop(context.Background())
+	if !reflect.DeepEqual(items(), [][]int{{1, 2}, {3}}) {
+		t.Fatalf("unexpected batches %v", items())
+	}
+}
+
+// TestOperatorWindowTakeReduce 滑动窗口/截取/聚合测试
+func TestOperatorWindowTakeReduce(t *testing.T) {
+	src := source(7)
+	windows, _ := sink(flow.Window(src, 3, 2))
+	src.Stop(context.Background())
+	if !reflect.DeepEqual(windows(), [][]int{{0, 1, 2}, {2, 3, 4}, {4, 5, 6}}) {
+		t.Fatalf("unexpected windows %v", windows())
+	}
+
+	src = source(1000)
+	taken := flow.Take(src, 5)
+	items, _ := sink(taken)
+	// 取满后输出流提前关闭
+	for taken.Status() != flow.ClosedState {
+		time.Sleep(time.Millisecond)
+	}
+	if !reflect.DeepEqual(items(), []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("unexpected take %v", items())
+	}
+	src.Stop(context.Background())
+
+	src = source(101)
+	sum, _ := sink(flow.Reduce(src, 0, func(acc, v int) int { return acc + v }))
+	src.Stop(context.Background())
+	if !reflect.DeepEqual(sum(), []int{5050}) {
+		t.Fatalf("unexpected reduce %v", sum())
+	}
+}
+
+// TestOperatorMergeZip 合并及配对测试
+func TestOperatorMergeZip(t *testing.T) {
+	a, b := source(10), source(10)
+	items, _ := sink(flow.Merge(a, b))
+	a.Stop(context.Background())
+	b.Stop(context.Background())
+	merged := items()
+	sort.Ints(merged)
+	if len(merged) != 20 || merged[0] != 0 || merged[19] != 9 {
+		t.Fatalf("unexpected merge %v", merged)
+	}
+
+	a, c := source(5), flow.NewDataFlow[string](8)
+	c.Produce(func(dc chan<- string, ec chan<- error, args ...any) {
+		for _, s := range []string{"a", "b", "c"} {
+			dc <- s
+		}
+	})
+	zipped := flow.Zip(a, c)
+	pairs, _ := sink(zipped)
+	c.Stop(context.Background())
+	a.Stop(context.Background())
+	// Zip 输出流在两个输入流都关闭且配对完成后异步关闭
+	<-zipped.Done()
+	expected := []flow.Pair[int, string]{{First: 0, Second: "a"}, {First: 1, Second: "b"}, {First: 2, Second: "c"}}
+	if !reflect.DeepEqual(pairs(), expected) {
+		t.Fatalf("unexpected zip %v", pairs())
+	}
+}
+
+// TestOperatorStopOutput 输入流运行中直接关闭输出流测试 (等待输入流关闭, 不丢数据)
+func TestOperatorStopOutput(t *testing.T) {
+	src := source(200)
+	dst := flow.Map(src, func(v int) (int, error) { return v, nil })
+	items, _ := sink(dst)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = dst.Stop(context.Background())
+	}()
+	_ = src.Stop(context.Background())
+	<-stopped
+	if got := items(); len(got) != 200 {
+		t.Fatalf("expected 200 items, got %d", len(got))
+	}
+	for _, fn := range []func(){
+		func() { flow.Window(source(1), 0, 1) },
+		func() { flow.Batch(source(1), 0, 0) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic on invalid argument")
+				}
+			}()
+			fn()
+		}()
+	}
+}
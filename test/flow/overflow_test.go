@@ -0,0 +1,147 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/flow"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  flow 溢出策略及指标 测试                                                          //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.09 10:26:41                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// overflowFlow 写满缓冲后再开始消费, 返回按顺序读到的数据
+func overflowFlow(t *testing.T, conf *flow.FlowConfig[int], n int) (*flow.DataFlow[int], []int) {
+	f := flow.NewDataFlowWithConfig[int](conf)
+	f.Produce(func(dc chan<- int, ec chan<- error, args ...any) {
+		for i := 0; i < n; i++ {
+			dc <- i
+		}
+	})
+	waitFor(t, func() bool { return f.Metrics().ItemsIn == int64(n) })
+	var items []int
+	f.Consume(func(dc <-chan int, ec chan<- error, args ...any) {
+		for v := range dc {
+			items = append(items, v)
+		}
+	})
+	if err := f.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return f, items
+}
+
+// waitFor 等待条件满足
+func waitFor(t *testing.T, cond func() bool) {
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting for condition")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestOverflowDrop 丢弃新数据及丢弃最旧数据策略测试
+func TestOverflowDrop(t *testing.T) {
+	var dropped int64
+	onDrop := func(item int) { atomic.AddInt64(&dropped, 1) }
+	f, items := overflowFlow(t, &flow.FlowConfig[int]{BufSize: 2, Overflow: flow.OverflowDropNewest, OnDrop: onDrop}, 10)
+	if !reflect.DeepEqual(items, []int{0, 1}) || dropped != 8 || f.Metrics().Dropped != 8 {
+		t.Fatalf("drop newest: got %v, dropped %d", items, dropped)
+	}
+	f, items = overflowFlow(t, &flow.FlowConfig[int]{BufSize: 2, Overflow: flow.OverflowDropOldest}, 10)
+	if m := f.Metrics(); !reflect.DeepEqual(items, []int{8, 9}) || m.Dropped != 8 || m.ItemsOut != 2 {
+		t.Fatalf("drop oldest: got %v, metrics %s", items, m)
+	}
+}
+
+// TestOverflowSpill 溢出到磁盘策略测试 (按写入顺序回填, 关闭后删除溢出文件)
+func TestOverflowSpill(t *testing.T) {
+	dir := t.TempDir()
+	f, items := overflowFlow(t, &flow.FlowConfig[int]{BufSize: 2, Overflow: flow.OverflowSpill, SpillDir: dir}, 100)
+	if len(items) != 100 {
+		t.Fatalf("expected 100 items, got %d", len(items))
+	}
+	for i, v := range items {
+		if v != i {
+			t.Fatalf("expected item %d at %d, got %d", i, i, v)
+		}
+	}
+	if m := f.Metrics(); m.Spilled != 98 || m.SpillDepth != 0 || m.ItemsOut != 100 || m.Dropped != 0 {
+		t.Fatalf("unexpected metrics: %s", m)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("expected spill file removed, got %d entries", len(entries))
+	}
+}
+
+// TestFlowMetrics 指标快照及导出测试
+func TestFlowMetrics(t *testing.T) {
+	exports := make(chan flow.FlowMetrics, 16)
+	f := flow.NewDataFlowWithConfig[int](&flow.FlowConfig[int]{
+		BufSize:   1,
+		OnMetrics: func(m flow.FlowMetrics) { exports <- m },
+	})
+	f.Produce(func(dc chan<- int, ec chan<- error, args ...any) {
+		for i := 0; i < 20; i++ {
+			dc <- i
+		}
+		ec <- errors.New("produce failed")
+	})
+	f.Consume(func(dc <-chan int, ec chan<- error, args ...any) {
+		for v := range dc {
+			time.Sleep(time.Millisecond)
+			if v%10 == 0 {
+				ec <- errors.New("consume failed")
+			}
+		}
+	})
+	var errs int64
+	f.OnError(func(ec <-chan error, args ...any) {
+		for range ec {
+			atomic.AddInt64(&errs, 1)
+		}
+	})
+	if err := f.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	m := <-exports
+	if m.ItemsIn != 20 || m.ItemsOut != 20 || m.QueueDepth != 0 || m.QueueCap != 1 || m.Errors != 3 {
+		t.Fatalf("unexpected metrics: %s", m)
+	}
+	if m.State != flow.ClosedState || m.BlockedTime <= 0 || m.InRate <= 0 || m.Throughput <= 0 {
+		t.Fatalf("unexpected metrics: %s", m)
+	}
+	if _, err := m.JSON(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestOverflowEdgeConfig 异常指标窗口及无缓冲数据流丢弃最旧数据测试
+func TestOverflowEdgeConfig(t *testing.T) {
+	conf := &flow.FlowConfig[int]{MetricsWindow: -time.Second, Overflow: flow.OverflowDropOldest}
+	f, items := overflowFlow(t, conf, 1)
+	if conf.MetricsWindow != -time.Second || f.Metrics().Window <= 0 {
+		t.Fatalf("unexpected metrics window: %s / %s", conf.MetricsWindow, f.Metrics().Window)
+	}
+	// 无缓冲数据流退化为阻塞, 数据不丢失
+	if !reflect.DeepEqual(items, []int{0}) || f.Metrics().Dropped != 0 {
+		t.Fatalf("expected blocking fallback, got %v", items)
+	}
+}
@@ -0,0 +1,146 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/async"
+	"github.com/Anonymouscn/go-partner/flow"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  flow 并行消费 测试                                                                //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.08 11:17:52                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// TestConsumeParallel 并行消费方完成跟踪及 panic 恢复测试
+func TestConsumeParallel(t *testing.T) {
+	f := source(1000)
+	var sum, panics int64
+	f.OnError(func(ec <-chan error, args ...any) {
+		for err := range ec {
+			var pe *async.PanicError
+			if errors.As(err, &pe) {
+				atomic.AddInt64(&panics, 1)
+			}
+		}
+	})
+	f.ConsumeParallel(4, func(dc <-chan int, ec chan<- error, args ...any) {
+		for v := range dc {
+			if v%100 == 0 {
+				panic("boom " + strconv.Itoa(v))
+			}
+			atomic.AddInt64(&sum, int64(v))
+		}
+	})
+	f.Stop(context.Background())
+	// 0..999 之和减去 panic 丢弃的 100 的倍数
+	if sum != 499500-4500 {
+		t.Fatalf("unexpected sum %d", sum)
+	}
+	if f.Consumers != 0 || f.Status() != flow.ClosedState {
+		t.Fatalf("unexpected state: consumers=%d state=%d", f.Consumers, f.Status())
+	}
+	for i := 0; i < 100 && atomic.LoadInt64(&panics) < 10; i++ {
+		time.Sleep(time.Millisecond)
+	}
+	if panics != 10 {
+		t.Fatalf("expected 10 recovered panics, got %d", panics)
+	}
+}
+
+// TestParallelMapOrdered 有序并行处理测试
+func TestParallelMapOrdered(t *testing.T) {
+	src := source(200)
+	out := flow.ParallelMap(src, func(v int) (int, error) {
+		time.Sleep(time.Duration(v%5) * time.Millisecond)
+		if v == 50 {
+			panic("bad item")
+		}
+		return v * 2, nil
+	}, &flow.ParallelConfig[int]{Workers: 8, Mode: flow.ParallelOrdered})
+	items, errs := sink(out)
+	src.Stop(context.Background())
+	res := items()
+	if len(res) != 199 {
+		t.Fatalf("expected 199 items, got %d", len(res))
+	}
+	for i := 1; i < len(res); i++ {
+		if res[i] <= res[i-1] {
+			t.Fatalf("order broken at %d: %v", i, res[i-1:i+1])
+		}
+	}
+	if len(errs()) != 1 {
+		t.Fatalf("expected 1 panic error, got %v", errs())
+	}
+}
+
+// TestConsumeEachKeyed 按 key 分组并行消费测试
+func TestConsumeEachKeyed(t *testing.T) {
+	f := source(300)
+	var lock sync.Mutex
+	seen := make(map[string][]int)
+	active := make(map[string]bool)
+	var overlap int64
+	f.ConsumeEach(func(v int) error {
+		key := strconv.Itoa(v % 3)
+		lock.Lock()
+		if active[key] {
+			atomic.AddInt64(&overlap, 1)
+		}
+		active[key] = true
+		lock.Unlock()
+		time.Sleep(50 * time.Microsecond)
+		lock.Lock()
+		active[key] = false
+		seen[key] = append(seen[key], v)
+		lock.Unlock()
+		return nil
+	}, &flow.ParallelConfig[int]{Workers: 4, Mode: flow.ParallelKeyed, KeyFn: func(v int) string { return strconv.Itoa(v % 3) }})
+	f.Stop(context.Background())
+	if overlap != 0 {
+		t.Fatalf("same key processed concurrently %d times", overlap)
+	}
+	for key, list := range seen {
+		if len(list) != 100 {
+			t.Fatalf("key %s: expected 100 items, got %d", key, len(list))
+		}
+		for i := 1; i < len(list); i++ {
+			if list[i] < list[i-1] {
+				t.Fatalf("key %s: order broken %v", key, list[i-1:i+1])
+			}
+		}
+	}
+}
+
+// TestParallelConfig 配置校验及不修改调用方配置测试
+func TestParallelConfig(t *testing.T) {
+	conf := &flow.ParallelConfig[int]{Mode: flow.ParallelOrdered}
+	out := flow.ParallelMap(source(10), func(v int) (int, error) { return v, nil }, conf)
+	out.Consume(func(dc <-chan int, ec chan<- error, args ...any) {
+		for range dc {
+		}
+	})
+	if conf.Workers != 0 {
+		t.Fatalf("expected caller config untouched, got %d workers", conf.Workers)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic without KeyFn")
+		}
+	}()
+	source(1).ConsumeEach(func(int) error { return nil }, &flow.ParallelConfig[int]{Mode: flow.ParallelKeyed})
+}
@@ -0,0 +1,176 @@
+package flow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Anonymouscn/go-partner/flow"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  flow 磁盘队列 测试                                                                //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.10 15:08:23                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// openQueue 打开磁盘队列
+func openQueue(t *testing.T, dir string, segmentSize int64) *flow.DiskQueue[int] {
+	q, err := flow.OpenDiskQueue[int](&flow.QueueConfig[int]{Dir: dir, SegmentSize: segmentSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return q
+}
+
+// drainQueue 读出全部待投递消息
+func drainQueue(t *testing.T, q *flow.DiskQueue[int]) []*flow.Message[int] {
+	var msgs []*flow.Message[int]
+	for {
+		msg, ok, err := q.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return msgs
+		}
+		msgs = append(msgs, msg)
+	}
+}
+
+// items 消息数据
+func items(msgs []*flow.Message[int]) []int {
+	res := make([]int, 0, len(msgs))
+	for _, msg := range msgs {
+		res = append(res, msg.Item)
+	}
+	return res
+}
+
+// TestDiskQueueRecovery 未确认消息重新投递及末尾残缺记录恢复测试
+func TestDiskQueueRecovery(t *testing.T) {
+	dir := t.TempDir()
+	conf := &flow.QueueConfig[int]{Dir: dir}
+	q, err := flow.OpenDiskQueue[int](conf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.Codec != nil || conf.SegmentSize != 0 || conf.CheckpointEvery != 0 {
+		t.Fatalf("queue config mutated: %+v", conf)
+	}
+	for i := 0; i < 10; i++ {
+		if err := q.Push(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	msgs := drainQueue(t, q)[:5]
+	for _, i := range []int{0, 1, 3} {
+		if err := msgs[i].Ack(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := msgs[0].Ack(); err != flow.ErrMessageNotFound {
+		t.Fatalf("expected ErrMessageNotFound, got %v", err)
+	}
+	_ = q.Close()
+	// 模拟崩溃: 末尾段写入一半的记录 (记录头中的长度已损坏)
+	segs, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	file, _ := os.OpenFile(segs[len(segs)-1], os.O_WRONLY|os.O_APPEND, 0o644)
+	_, _ = file.Write([]byte{0xff, 0xff, 0xff, 0xf0, 1, 2, 3, 4, 0, 0, 0, 0, 0, 0, 0, 10, 1, 2})
+	_ = file.Close()
+
+	q = openQueue(t, dir, 0)
+	defer q.Close()
+	if q.Len() != 7 {
+		t.Fatalf("expected 7 pending, got %d", q.Len())
+	}
+	if err := q.Push(10); err != nil {
+		t.Fatal(err)
+	}
+	if got := items(drainQueue(t, q)); !reflect.DeepEqual(got, []int{2, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Fatalf("unexpected redelivery: %v", got)
+	}
+}
+
+// TestDiskQueueCompaction 段文件删除及重写测试
+func TestDiskQueueCompaction(t *testing.T) {
+	dir := t.TempDir()
+	q := openQueue(t, dir, 64)
+	for i := 0; i < 40; i++ {
+		_ = q.Push(i)
+	}
+	msgs := drainQueue(t, q)
+	// 确认前一半: 全部确认的段文件被删除
+	for _, msg := range msgs[:20] {
+		_ = msg.Ack()
+	}
+	segs, _ := filepath.Glob(filepath.Join(dir, "*.seg"))
+	total := len(segs)
+	// 确认后一半中的偶数项并压缩: 段文件只保留未确认的记录
+	for _, msg := range msgs[20:] {
+		if msg.Item%2 == 0 {
+			_ = msg.Ack()
+		}
+	}
+	if err := q.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	var size int64
+	segs, _ = filepath.Glob(filepath.Join(dir, "*.seg"))
+	for _, seg := range segs {
+		info, _ := os.Stat(seg)
+		size += info.Size()
+	}
+	if total >= 20 || size > 64*int64(total)/2+64 {
+		t.Fatalf("expected acked segments compacted, got %d segments / %d bytes", total, size)
+	}
+	_ = q.Close()
+	q = openQueue(t, dir, 64)
+	defer q.Close()
+	if got := items(drainQueue(t, q)); !reflect.DeepEqual(got, []int{21, 23, 25, 27, 29, 31, 33, 35, 37, 39}) {
+		t.Fatalf("unexpected redelivery after compaction: %v", got)
+	}
+}
+
+// TestDiskQueueFlow 磁盘队列作为数据流缓冲测试
+func TestDiskQueueFlow(t *testing.T) {
+	q := openQueue(t, t.TempDir(), 0)
+	defer q.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	var sum, acked int64
+	f := q.Flow(ctx, 4).Consume(func(dc <-chan *flow.Message[int], ec chan<- error, args ...any) {
+		for msg := range dc {
+			atomic.AddInt64(&sum, int64(msg.Item))
+			if err := msg.Ack(); err != nil {
+				ec <- err
+			}
+			atomic.AddInt64(&acked, 1)
+		}
+	})
+	for i := 1; i <= 100; i++ {
+		_ = q.Push(i)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt64(&acked) == 100 })
+	cancel()
+	<-f.Done()
+	if sum != 5050 || q.Len() != 0 || q.Unacked() != 0 {
+		t.Fatalf("unexpected state: sum %d, pending %d, unacked %d", sum, q.Len(), q.Unacked())
+	}
+
+	// 作为溢出队列使用
+	spill := openQueue(t, t.TempDir(), 0)
+	_, got := overflowFlow(t, &flow.FlowConfig[int]{BufSize: 1, Overflow: flow.OverflowSpill, SpillQueue: spill}, 20)
+	if len(got) != 20 || got[19] != 19 {
+		t.Fatalf("unexpected spill output: %v", got)
+	}
+}
@@ -0,0 +1,107 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/flow"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  flow 处理阶段重试及死信 测试                                                      //
+//  @author anonymous                                                               //
+//  @updated_at 2024.12.11 16:42:05                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+var errFlaky = errors.New("flaky")
+
+// TestStageRetryDeadLetter 重试成功及重试耗尽转入死信流测试
+func TestStageRetryDeadLetter(t *testing.T) {
+	var lock sync.Mutex
+	attempts := make(map[int]int)
+	dlq := flow.NewDataFlow[*flow.ItemError[int]](4)
+	var dead []*flow.ItemError[int]
+	dlq.Consume(func(dc <-chan *flow.ItemError[int], ec chan<- error, args ...any) {
+		for err := range dc {
+			dead = append(dead, err)
+		}
+	})
+	src := source(20)
+	out := flow.Stage(src, func(v int) (int, error) {
+		lock.Lock()
+		attempts[v]++
+		n := attempts[v]
+		lock.Unlock()
+		// 5 的倍数始终失败, 奇数前两次失败
+		if v%5 == 0 || (v%2 == 1 && n < 3) {
+			return 0, errFlaky
+		}
+		return v * 10, nil
+	}, &flow.StageConfig[int]{
+		Name:       "parse",
+		Retry:      &flow.RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond, Jitter: 0.5},
+		DeadLetter: flow.DeadLetterFlow(dlq),
+	})
+	items, errs := sink(out)
+	_ = src.Stop(context.Background())
+	_ = dlq.Stop(context.Background())
+	if got := items(); len(got) != 16 || len(errs()) != 0 {
+		t.Fatalf("expected 16 items and no errors, got %v / %v", got, errs())
+	}
+	sort.Slice(dead, func(i, j int) bool { return dead[i].Item < dead[j].Item })
+	if len(dead) != 4 {
+		t.Fatalf("expected 4 dead letters, got %d", len(dead))
+	}
+	for i, err := range dead {
+		if err.Item != i*5 || err.Stage != "parse" || err.Attempt != 3 || !errors.Is(err, errFlaky) {
+			t.Fatalf("unexpected dead letter: %+v", err)
+		}
+	}
+}
+
+// TestConsumeStage 不重试的错误及无死信时写入错误管道测试
+func TestConsumeStage(t *testing.T) {
+	var calls int64
+	errFatal := errors.New("fatal")
+	conf := &flow.StageConfig[int]{
+		Retry: &flow.RetryPolicy{MaxAttempts: 5, RetryIf: func(err error) bool { return err != errFatal }},
+	}
+	f := source(5).ConsumeStage(func(v int) error {
+		atomic.AddInt64(&calls, 1)
+		if v == 3 {
+			return errFatal
+		}
+		return nil
+	}, conf)
+	var failed []*flow.ItemError[int]
+	done := make(chan struct{})
+	f.OnError(func(ec <-chan error, args ...any) {
+		defer close(done)
+		for err := range ec {
+			if itemErr, ok := flow.AsItemError[int](err); ok {
+				failed = append(failed, itemErr)
+			}
+		}
+	})
+	_ = f.Stop(context.Background())
+	<-done
+	if calls != 5 || len(failed) != 1 || failed[0].Item != 3 || failed[0].Stage != "stage" || failed[0].Attempt != 1 {
+		t.Fatalf("unexpected result: calls %d, failed %+v", calls, failed)
+	}
+	// 默认值不写回调用方配置
+	if conf.Name != "" || conf.Retry.Backoff != 0 || conf.Retry.Multiplier != 0 {
+		t.Fatalf("stage config mutated: %+v / %+v", conf, conf.Retry)
+	}
+}
@@ -0,0 +1,78 @@
+package test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	customerror "github.com/Anonymouscn/go-partner/error"
+	"github.com/Anonymouscn/go-partner/restful"
+	restful_model "github.com/Anonymouscn/go-partner/restful/model"
+	"github.com/Anonymouscn/go-partner/web"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  错误码与 problem+json 测试                                                        //
+//  @author anonymous                                                               //
+//  @updated_at 2024.11.27 09:52:36                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// codeUserNotFound 用户不存在错误码
+var codeUserNotFound = restful_model.RegisterErrorCode(10404, http.StatusNotFound, "User Not Found", "user.not_found")
+
+// TestFromError 根据错误构建业务结果测试
+func TestFromError(t *testing.T) {
+	err := fmt.Errorf("query user: %w", restful_model.NewError(codeUserNotFound, "user 42 not found"))
+	res := restful_model.FromError(err)
+	if res.Code != 10404 || res.Message != "user 42 not found" || restful_model.StatusOf(err) != http.StatusNotFound {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	res = restful_model.FromError(errors.New("db connection refused"))
+	if res.Code != 500 || res.Message != "Internal Server Error" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+	restful_model.SetTranslator(func(locale, key string) (string, bool) {
+		if locale == "zh-CN" && key == "user.not_found" {
+			return "用户不存在", true
+		}
+		return "", false
+	})
+	defer restful_model.SetTranslator(nil)
+	if res = restful_model.FromErrorWithLocale(codeUserNotFound, "zh-CN"); res.Message != "用户不存在" {
+		t.Errorf("unexpected localized result: %+v", res)
+	}
+}
+
+// TestProblemRoundTrip 服务端输出 problem+json, 客户端解析测试
+func TestProblemRoundTrip(t *testing.T) {
+	s := web.NewRestServer()
+	s.GET("/users/:id", func(ctx *web.RestContext) {
+		ctx.Problem(restful_model.NewError(codeUserNotFound, "user "+ctx.Param("id")+" not found"))
+	})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	_, err := restful.NewRestClient().SetURL(srv.URL + "/users/42").Get().Stringify()
+	var (
+		fail    *customerror.RequestFail
+		problem *restful_model.Problem
+	)
+	if !errors.As(err, &fail) || !errors.As(err, &problem) {
+		t.Fatalf("expected problem error, got %v", err)
+	}
+	if problem.Status != 404 || problem.Code != 10404 || problem.Title != "User Not Found" ||
+		problem.Detail != "user 42 not found" || problem.Instance != "/users/42" {
+		t.Errorf("unexpected problem: %+v", problem)
+	}
+	if res := problem.ToResult(); res.Code != 10404 || res.Message != "user 42 not found" {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Anonymouscn/go-partner/restful"
+	restful_model "github.com/Anonymouscn/go-partner/restful/model"
+	"github.com/Anonymouscn/go-partner/web"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  分页结果 测试                                                                     //
+//  @author anonymous                                                               //
+//  @updated_at 2024.11.27 15:08:11                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// PageUser 分页测试用户
+type PageUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// TestMapPage 分页结果类型转换测试
+func TestMapPage(t *testing.T) {
+	p := restful_model.NewPage([]int{1, 2, 3}, 7, 1, 3)
+	if !p.HasMore || p.TotalPages() != 3 {
+		t.Errorf("unexpected page: %+v", p)
+	}
+	names := restful_model.MapPage(p, func(id int) string { return "user-" + strconv.Itoa(id) })
+	if len(names.Items) != 3 || names.Items[2] != "user-3" || names.Total != 7 || names.Page != 1 {
+		t.Errorf("unexpected mapped page: %+v", names)
+	}
+	c := restful_model.NewCursorPage([]int{4}, "", 3, -1)
+	if c.HasMore || c.TotalPages() != -1 {
+		t.Errorf("unexpected cursor page: %+v", c)
+	}
+}
+
+// TestPageRoundTrip 服务端输出分页结果, 客户端解析测试
+func TestPageRoundTrip(t *testing.T) {
+	s := web.NewRestServer()
+	s.GET("/users", func(ctx *web.RestContext) {
+		q := ctx.PageQuery()
+		items := []*PageUser{{ID: q.Offset() + 1, Name: "tom"}}
+		ctx.SuccessWithData(restful_model.NewPage(items, 10, q.Page, q.Size))
+	})
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+	resp := &restful_model.Result[restful_model.PageResult[*PageUser]]{}
+	err := restful.NewRestClient().SetURL(srv.URL + "/users").SetQuery(restful.Data{"page": 2, "size": 5}).Get().Bind(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := resp.Data
+	if p.Page != 2 || p.Size != 5 || p.Total != 10 || p.HasMore || len(p.Items) != 1 || p.Items[0].ID != 6 {
+		t.Errorf("unexpected page: %+v", p)
+	}
+}
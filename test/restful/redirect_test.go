@@ -0,0 +1,136 @@
+package test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Anonymouscn/go-partner/restful"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  rest client 重定向策略 测试                                                       //
+//  @author anonymous                                                               //
+//  @updated_at 2024.11.24 16:12:31                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// newRedirectServer 新建重定向测试服务 (/a -> /b -> /c, /cross -> target)
+func newRedirectServer(target string) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/b", http.StatusFound)
+	})
+	mux.HandleFunc("/b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/c", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/c", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"auth":"` + r.Header.Get("Authorization") + `"}`))
+	})
+	mux.HandleFunc("/cross", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target+"/c", http.StatusFound)
+	})
+	return httptest.NewServer(mux)
+}
+
+// TestRedirectChain 重定向链记录测试
+func TestRedirectChain(t *testing.T) {
+	srv := newRedirectServer("")
+	defer srv.Close()
+	rc := restful.NewRestClient().SetURL(srv.URL + "/a").Get()
+	if _, err := rc.Stringify(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chain := rc.GetResponseStack()[0].Redirects
+	if len(chain) != 2 {
+		t.Fatalf("expected 2 redirects, got %d", len(chain))
+	}
+	if chain[0].URL != srv.URL+"/a" || chain[0].StatusCode != http.StatusFound {
+		t.Errorf("unexpected first hop: %+v", chain[0])
+	}
+	if chain[1].URL != srv.URL+"/b" || chain[1].StatusCode != http.StatusMovedPermanently {
+		t.Errorf("unexpected second hop: %+v", chain[1])
+	}
+}
+
+// TestDisableRedirect 禁用重定向测试
+func TestDisableRedirect(t *testing.T) {
+	srv := newRedirectServer("")
+	defer srv.Close()
+	rc := restful.NewRestClient().SetURL(srv.URL + "/a").DisableRedirect().Get()
+	resp := rc.GetResponseStack()[0]
+	if resp.Err != nil || resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected raw 302 response, got %v %v", resp.StatusCode, resp.Err)
+	}
+	if loc := resp.Headers.Get("Location"); loc != "/b" {
+		t.Errorf("unexpected location: %v", loc)
+	}
+}
+
+// TestMaxRedirects 最大重定向次数测试
+func TestMaxRedirects(t *testing.T) {
+	srv := newRedirectServer("")
+	defer srv.Close()
+	rc := restful.NewRestClient().SetURL(srv.URL + "/a").SetMaxRedirects(1).Get()
+	if _, err := rc.Stringify(); err == nil {
+		t.Fatal("expected redirect limit error")
+	}
+}
+
+// TestCrossHostRedirect 跨主机重定向请求头转发测试
+func TestCrossHostRedirect(t *testing.T) {
+	target := newRedirectServer("")
+	defer target.Close()
+	// 目标使用不同主机名 (localhost / 127.0.0.1), net/http 默认不转发 Authorization
+	srv := newRedirectServer(strings.Replace(target.URL, "127.0.0.1", "localhost", 1))
+	defer srv.Close()
+	rc := restful.NewRestClient().
+		SetURL(srv.URL + "/cross").
+		SetHeaders(restful.Data{"Authorization": "token"}).
+		Get()
+	if res, _ := rc.Stringify(); res != `{"auth":""}` {
+		t.Errorf("authorization should not be forwarded by default, got %v", res)
+	}
+	// 强制移除优先于受信主机转发
+	rc = restful.NewRestClient().
+		SetURL(srv.URL + "/cross").
+		SetHeaders(restful.Data{"Authorization": "token"}).
+		ApplyRedirectPolicy(&restful.RedirectPolicy{TrustedHosts: []string{"localhost"}, StripHeaders: []string{"Authorization"}}).
+		Get()
+	if res, _ := rc.Stringify(); res != `{"auth":""}` {
+		t.Errorf("authorization should be stripped, got %v", res)
+	}
+	// 受信主机按主机名匹配, 转发全部请求头
+	rc = restful.NewRestClient().
+		SetURL(srv.URL + "/cross").
+		SetHeaders(restful.Data{"Authorization": "token"}).
+		ApplyRedirectPolicy(&restful.RedirectPolicy{TrustedHosts: []string{"localhost"}}).
+		Get()
+	if res, _ := rc.Stringify(); res != `{"auth":"token"}` {
+		t.Errorf("authorization should be forwarded to trusted host, got %v", res)
+	}
+	// 显式转发 Authorization
+	rc = restful.NewRestClient().
+		SetURL(srv.URL + "/cross").
+		SetHeaders(restful.Data{"Authorization": "token"}).
+		ApplyRedirectPolicy(&restful.RedirectPolicy{ForwardHeaders: []string{"Authorization"}}).
+		Get()
+	if res, _ := rc.Stringify(); res != `{"auth":"token"}` {
+		t.Errorf("authorization should be forwarded, got %v", res)
+	}
+	// 仅允许同主机重定向
+	rc = restful.NewRestClient().
+		SetURL(srv.URL + "/cross").
+		ApplyRedirectPolicy(&restful.RedirectPolicy{SameHostOnly: true}).
+		Get()
+	if _, err := rc.Stringify(); err == nil {
+		t.Error("expected cross-host redirect to be rejected")
+	}
+}
@@ -0,0 +1,176 @@
+package validate
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Anonymouscn/go-partner/validate"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  validate 结构体校验 测试                                                           //
+//  @author anonymous                                                               //
+//  @updated_at 2024.11.26 11:40:15                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// Contact 联系方式
+type Contact struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// CreateUserReq 新建用户请求
+type CreateUserReq struct {
+	Name     string     `json:"name" validate:"required,min=1,max=8"`
+	Role     string     `json:"role" validate:"oneof=admin user"`
+	Age      *int       `json:"age" validate:"omitempty,min=18"`
+	Contacts []*Contact `json:"contacts" validate:"max=2"`
+	Remark   string     `json:"remark"`
+}
+
+// collectPaths 收集错误路径
+func collectPaths(err error) map[string]string {
+	res := make(map[string]string)
+	var errs validate.ValidationErrors
+	if errors.As(err, &errs) {
+		for _, e := range errs {
+			res[e.Path] = e.Rule
+		}
+	}
+	return res
+}
+
+// TestStruct 结构体校验测试
+func TestStruct(t *testing.T) {
+	age := 16
+	err := validate.Struct(&CreateUserReq{
+		Name:     "a-very-long-name",
+		Role:     "guest",
+		Age:      &age,
+		Contacts: []*Contact{{Email: "tom@example.com"}, {Email: "bad-email"}, {}},
+	})
+	expected := map[string]string{
+		"name":              "max",
+		"role":              "oneof",
+		"age":               "min",
+		"contacts":          "max",
+		"contacts[1].email": "email",
+		"contacts[2].email": "required",
+	}
+	paths := collectPaths(err)
+	if len(paths) != len(expected) {
+		t.Fatalf("unexpected errors: %v", err)
+	}
+	for path, r := range expected {
+		if paths[path] != r {
+			t.Errorf("expected %v to fail on %v, got %v", path, r, paths[path])
+		}
+	}
+	if err := validate.Struct(&CreateUserReq{Name: "tom", Role: "user"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestCheckTypes 字段类型校验测试
+func TestCheckTypes(t *testing.T) {
+	m := map[string]any{
+		"name":     float64(1),
+		"age":      18.5,
+		"contacts": []any{map[string]any{"email": true}},
+	}
+	paths := collectPaths(validate.CheckTypes(m, &CreateUserReq{}))
+	for _, path := range []string{"name", "age", "contacts[0].email"} {
+		if paths[path] != "type" {
+			t.Errorf("expected type error on %v, got %v", path, paths)
+		}
+	}
+}
+
+// Audit 审计字段
+type Audit struct {
+	Operator string `json:"operator" validate:"required"`
+}
+
+// UpdateUserReq 更新用户请求 (匿名结构字段)
+type UpdateUserReq struct {
+	Audit
+	*Contact
+	Name string `json:"name" validate:"max=8"`
+}
+
+// BadRuleReq 未知规则请求
+type BadRuleReq struct {
+	Name string `json:"name" validate:"unknown"`
+}
+
+// BadParamReq 无效规则参数请求
+type BadParamReq struct {
+	Items []*struct {
+		Name string `json:"name" validate:"min=one"`
+	} `json:"items"`
+}
+
+// TestStructEmbedded 匿名结构字段展开校验测试
+func TestStructEmbedded(t *testing.T) {
+	paths := collectPaths(validate.Struct(&UpdateUserReq{Contact: &Contact{Email: "bad-email"}}))
+	if len(paths) != 2 || paths["operator"] != "required" || paths["email"] != "email" {
+		t.Errorf("unexpected errors: %v", paths)
+	}
+	if err := validate.Struct(&UpdateUserReq{Audit: Audit{Operator: "tom"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestRegister 无效校验标签测试 (注册时返回错误, 校验时不 panic)
+func TestRegister(t *testing.T) {
+	if err := validate.Register(&CreateUserReq{}, &UpdateUserReq{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, obj := range []any{&BadRuleReq{}, &BadParamReq{}} {
+		if err := validate.Register(obj); err == nil {
+			t.Errorf("expected register error for %T", obj)
+		}
+	}
+	err := validate.Struct(&BadParamReq{Items: []*struct {
+		Name string `json:"name" validate:"min=one"`
+	}{{Name: "tom"}}})
+	var errs validate.ValidationErrors
+	if err == nil || errors.As(err, &errs) {
+		t.Errorf("expected tag error, got %v", err)
+	}
+}
+
+// TicketReq 工单请求 (自定义规则)
+type TicketReq struct {
+	Code string `json:"code" validate:"ticket"`
+}
+
+// TestRegisterRuleLater 校验后注册及覆盖自定义规则测试
+func TestRegisterRuleLater(t *testing.T) {
+	req := &TicketReq{Code: "T-1"}
+	// 规则注册前校验失败, 不应永久缓存解析错误
+	if err := validate.Struct(req); err == nil {
+		t.Fatal("expected unknown rule error")
+	}
+	validate.RegisterRule("ticket", func(v reflect.Value, param string) bool {
+		return strings.HasPrefix(v.String(), "T-")
+	}, "%[1]v must be a ticket code")
+	if err := validate.Struct(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// 覆盖已在校验中使用的规则后立即生效
+	validate.RegisterRule("ticket", func(v reflect.Value, param string) bool {
+		return strings.HasPrefix(v.String(), "TK-")
+	}, "")
+	if paths := collectPaths(validate.Struct(req)); paths["code"] != "ticket" {
+		t.Errorf("expected overridden rule to fail, got %v", paths)
+	}
+}
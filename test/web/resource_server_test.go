@@ -0,0 +1,148 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Anonymouscn/go-partner/web"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  resource server 测试                                                             //
+//  @author anonymous                                                               //
+//  @updated_at 2024.11.25 16:03:42                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// newTestFS 新建测试文件系统
+func newTestFS() fstest.MapFS {
+	return fstest.MapFS{
+		"index.html":        {Data: []byte("<html>index</html>")},
+		"app.js":            {Data: []byte("console.log('hello world')")},
+		"app.js.gz":         {Data: []byte("gzip-content")},
+		"assets/logo.txt":   {Data: []byte("0123456789")},
+		"assets/readme.txt": {Data: []byte("readme")},
+	}
+}
+
+// serveResource 发送资源请求
+func serveResource(rs *web.ResourceServer, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	rs.ServeHTTP(w, req)
+	return w
+}
+
+// TestResourceServer 静态资源服务测试
+func TestResourceServer(t *testing.T) {
+	rs := web.NewResourceServerFS(newTestFS())
+	// 普通文件与 ETag
+	w := serveResource(rs, "/assets/logo.txt", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || w.Body.String() != "0123456789" || etag == "" {
+		t.Fatalf("unexpected response: %v %v %v", w.Code, w.Body.String(), etag)
+	}
+	// 条件请求
+	if w = serveResource(rs, "/assets/logo.txt", map[string]string{"If-None-Match": etag}); w.Code != http.StatusNotModified {
+		t.Errorf("expected 304, got %v", w.Code)
+	}
+	// Range 请求
+	w = serveResource(rs, "/assets/logo.txt", map[string]string{"Range": "bytes=2-4"})
+	if w.Code != http.StatusPartialContent || w.Body.String() != "234" {
+		t.Errorf("unexpected range response: %v %v", w.Code, w.Body.String())
+	}
+	// 路径穿越
+	if w = serveResource(rs, "/assets/../../etc/passwd", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %v", w.Code)
+	}
+	// 目录列表默认禁用
+	if w = serveResource(rs, "/assets/", nil); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %v", w.Code)
+	}
+	// 资源不存在
+	if w = serveResource(rs, "/users/1", nil); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %v", w.Code)
+	}
+}
+
+// TestResourceServerOptions 静态资源服务配置测试
+func TestResourceServerOptions(t *testing.T) {
+	rs := web.NewResourceServerFS(newTestFS()).ApplyConfig(&web.ResourceServerConfig{
+		SPA:           true,
+		Listing:       true,
+		Precompressed: true,
+	})
+	// SPA 回退
+	if w := serveResource(rs, "/users/1", nil); w.Code != 200 || w.Body.String() != "<html>index</html>" {
+		t.Errorf("unexpected spa fallback: %v %v", w.Code, w.Body.String())
+	}
+	// 目录列表
+	if w := serveResource(rs, "/assets/", nil); w.Code != 200 || w.Header().Get("Content-Type") != "text/html; charset=utf-8" {
+		t.Errorf("unexpected listing: %v %v", w.Code, w.Body.String())
+	}
+	// 预压缩文件
+	w := serveResource(rs, "/app.js", map[string]string{"Accept-Encoding": "br;q=0, gzip"})
+	if w.Body.String() != "gzip-content" || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("unexpected precompressed response: %v %v", w.Header(), w.Body.String())
+	}
+	if w = serveResource(rs, "/app.js", nil); w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("unexpected encoding without accept-encoding: %v", w.Header())
+	}
+}
+
+// TestResourceServerPrefix 路由前缀按路径段匹配及内容摘要测试
+func TestResourceServerPrefix(t *testing.T) {
+	fsys := newTestFS()
+	rs := web.NewResourceServerFS(fsys).ApplyConfig(&web.ResourceServerConfig{Prefix: "/static"})
+	w := serveResource(rs, "/static/app.js", nil)
+	etag := w.Header().Get("ETag")
+	if w.Code != 200 || etag == "" {
+		t.Fatalf("unexpected response: %v %v", w.Code, etag)
+	}
+	if w = serveResource(rs, "/static", nil); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/static/" {
+		t.Errorf("unexpected prefix root response: %v %v", w.Code, w.Header())
+	}
+	if w = serveResource(rs, "/static/", nil); w.Code != 200 || w.Body.String() != "<html>index</html>" {
+		t.Errorf("unexpected prefix index response: %v %v", w.Code, w.Body.String())
+	}
+	for _, p := range []string{"/staticapp.js", "/app.js", "/other/static/app.js"} {
+		if w = serveResource(rs, p, nil); w.Code != http.StatusNotFound {
+			t.Errorf("%v: expected 404, got %v", p, w.Code)
+		}
+	}
+	// 非 embed.FS 的文件内容可变, 内容摘要不缓存
+	fsys["app.js"].Data = []byte("console.log('changed')")
+	if w = serveResource(rs, "/static/app.js", map[string]string{"If-None-Match": etag}); w.Code != 200 || w.Header().Get("ETag") == etag {
+		t.Errorf("expected fresh etag after change, got %v %v", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+// TestResourceServerHandlerFn 通配路由下目录重定向测试
+func TestResourceServerHandlerFn(t *testing.T) {
+	fsys := newTestFS()
+	fsys["assets/sub/index.html"] = &fstest.MapFile{Data: []byte("<html>sub</html>")}
+	s := web.NewRestServer()
+	s.GET("/static/*filepath", web.NewResourceServerFS(fsys).HandlerFn("filepath"))
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+	if w := serve("/static/assets/sub"); w.Code != http.StatusMovedPermanently || w.Header().Get("Location") != "/static/assets/sub/" {
+		t.Errorf("unexpected redirect: %v %v", w.Code, w.Header())
+	}
+	if w := serve("/static/assets/sub/"); w.Code != 200 || w.Body.String() != "<html>sub</html>" {
+		t.Errorf("unexpected directory response: %v %v", w.Code, w.Body.String())
+	}
+}
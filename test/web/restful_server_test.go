@@ -0,0 +1,129 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Anonymouscn/go-partner/web"
+)
+
+// ================================================================================ //
+//                                                                                  //
+//  restful server 测试                                                              //
+//  @author anonymous                                                               //
+//  @updated_at 2024.11.25 10:21:07                                                 //
+//                                                                                  //
+//  @cmd_help:                                                                      //
+//  1. unit test:                                                                   //
+//     $ go test xxx                                                                //
+//                                                                                  //
+//                                                                                  //
+// ================================================================================ //
+
+// User 测试用户
+type User struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// doRequest 发送测试请求
+func doRequest(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+// TestRestServerRoute 路由测试
+func TestRestServerRoute(t *testing.T) {
+	s := web.NewRestServer()
+	trace := make([]string, 0)
+	s.Use(func(ctx *web.RestContext) {
+		trace = append(trace, "root")
+		ctx.Next()
+	})
+	api := s.Group("/api/v1", func(ctx *web.RestContext) {
+		trace = append(trace, "group")
+		ctx.Next()
+	})
+	api.GET("/users/:id", func(ctx *web.RestContext) {
+		ctx.SuccessWithData(ctx.Param("id"))
+	})
+	api.GET("/users/me", func(ctx *web.RestContext) {
+		ctx.SuccessWithData("me")
+	})
+	api.GET("/files/*path", func(ctx *web.RestContext) {
+		ctx.SuccessWithData(ctx.Param("path"))
+	})
+	api.POST("/users", func(ctx *web.RestContext) {
+		u := &User{}
+		if err := ctx.Bind(u); err != nil {
+			ctx.Reply(http.StatusBadRequest, err.Error())
+			return
+		}
+		ctx.ReplyWithData(201, "Created", u)
+	})
+	cases := []struct {
+		method, path, body string
+		status             int
+		resp               string
+	}{
+		{"GET", "/api/v1/users/42", "", 200, `{"code":200,"message":"Success","data":"42"}`},
+		{"GET", "/api/v1/users/me", "", 200, `{"code":200,"message":"Success","data":"me"}`},
+		{"GET", "/api/v1/files/a/b.txt", "", 200, `{"code":200,"message":"Success","data":"a/b.txt"}`},
+		{"POST", "/api/v1/users", `{"name":"tom","age":18}`, 201, `{"code":201,"message":"Created","data":{"name":"tom","age":18}}`},
+		{"DELETE", "/api/v1/users/42", "", 405, `{"code":405,"message":"Method Not Allowed","data":null}`},
+		{"GET", "/api/v2/users", "", 404, `{"code":404,"message":"Not Found","data":null}`},
+	}
+	for _, c := range cases {
+		w := doRequest(s, c.method, c.path, c.body)
+		if w.Code != c.status || w.Body.String() != c.resp {
+			t.Errorf("%v %v: got %v %v", c.method, c.path, w.Code, w.Body.String())
+		}
+	}
+	if strings.Join(trace[:2], ",") != "root,group" {
+		t.Errorf("unexpected middleware order: %v", trace)
+	}
+}
+
+// TestRestServerRecovery panic 恢复测试
+func TestRestServerRecovery(t *testing.T) {
+	s := web.NewRestServer()
+	s.Use(web.Recovery())
+	s.GET("/panic", func(ctx *web.RestContext) {
+		panic("boom")
+	})
+	w := doRequest(s, "GET", "/panic", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %v", w.Code)
+	}
+}
+
+// TestRestServerValidation 请求体校验测试
+func TestRestServerValidation(t *testing.T) {
+	type CreateUserReq struct {
+		Name string `json:"name" validate:"required,max=8"`
+		Age  int    `json:"age" validate:"min=18"`
+	}
+	s := web.NewRestServer()
+	s.POST("/users", func(ctx *web.RestContext) {
+		req := &CreateUserReq{}
+		if !ctx.MustBind(req) {
+			return
+		}
+		ctx.SuccessWithData(req)
+	})
+	w := doRequest(s, "POST", "/users", `{"age":"18"}`)
+	if w.Code != 400 || w.Body.String() != `{"code":400,"message":"Invalid Params","data":[{"path":"age","field":"Age","rule":"type","param":"integer","message":"age must be of type integer"}]}` {
+		t.Errorf("unexpected type error reply: %v %v", w.Code, w.Body.String())
+	}
+	w = doRequest(s, "POST", "/users", `{"age":16}`)
+	if w.Code != 400 || w.Body.String() != `{"code":400,"message":"Invalid Params","data":[{"path":"name","field":"Name","rule":"required","message":"name is required"},{"path":"age","field":"Age","rule":"min","param":"18","message":"age must be at least 18"}]}` {
+		t.Errorf("unexpected validation reply: %v %v", w.Code, w.Body.String())
+	}
+	if w = doRequest(s, "POST", "/users", `{"name":"tom","age":18}`); w.Code != 200 {
+		t.Errorf("unexpected reply: %v %v", w.Code, w.Body.String())
+	}
+}
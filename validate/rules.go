@@ -0,0 +1,161 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// RuleFn 校验规则方法
+// v: 字段值 (已解引用), param: 规则参数; 返回是否通过校验
+type RuleFn func(v reflect.Value, param string) bool
+
+var (
+	rulesLock sync.RWMutex // 规则表读写锁
+	// rules 校验规则表
+	rules = map[string]RuleFn{
+		"min":   minRule,
+		"max":   maxRule,
+		"len":   lenRule,
+		"email": emailRule,
+		"oneof": oneOfRule,
+	}
+	// params 内置规则参数校验 (解析标签时执行)
+	params = map[string]func(param string) error{
+		"min":   numberParam,
+		"max":   numberParam,
+		"len":   numberParam,
+		"oneof": requiredParam,
+	}
+	// messages 校验规则错误信息模板 (%[1]v: 字段路径, %[2]v: 规则参数)
+	messages = map[string]string{
+		"required": "%[1]v is required",
+		"min":      "%[1]v must be at least %[2]v",
+		"max":      "%[1]v must be at most %[2]v",
+		"len":      "%[1]v must have length %[2]v",
+		"email":    "%[1]v must be a valid email address",
+		"oneof":    "%[1]v must be one of [%[2]v]",
+		"type":     "%[1]v must be of type %[2]v",
+	}
+	// emailRegexp 邮箱格式
+	emailRegexp = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)+$`)
+)
+
+// RegisterRule 注册自定义校验规则 (同名覆盖, 覆盖内置规则时不再校验内置规则参数)
+// msg: 错误信息模板, %[1]v 为字段路径, %[2]v 为规则参数
+func RegisterRule(name string, fn RuleFn, msg string) {
+	rulesLock.Lock()
+	defer rulesLock.Unlock()
+	rules[name] = fn
+	delete(params, name)
+	if msg != "" {
+		messages[name] = msg
+	}
+}
+
+// lookupRule 查找校验规则
+func lookupRule(name string) RuleFn {
+	rulesLock.RLock()
+	defer rulesLock.RUnlock()
+	return rules[name]
+}
+
+// checkParam 校验规则参数
+func checkParam(name, param string) error {
+	rulesLock.RLock()
+	check := params[name]
+	rulesLock.RUnlock()
+	if check == nil {
+		return nil
+	}
+	return check(param)
+}
+
+// numberParam 数值参数
+func numberParam(param string) error {
+	if _, err := strconv.ParseFloat(param, 64); err != nil {
+		return fmt.Errorf("invalid rule param %q", param)
+	}
+	return nil
+}
+
+// requiredParam 非空参数
+func requiredParam(param string) error {
+	if strings.TrimSpace(param) == "" {
+		return fmt.Errorf("missing rule param")
+	}
+	return nil
+}
+
+// message 生成错误信息
+func message(path, name, param string) string {
+	rulesLock.RLock()
+	tpl, ok := messages[name]
+	rulesLock.RUnlock()
+	if !ok {
+		tpl = "%[1]v failed on rule " + name
+	}
+	return fmt.Sprintf(tpl, path, param)
+}
+
+// measure 获取用于比较的数值 (字符串: 字符数, 切片/数组/map: 长度, 数字: 数值)
+func measure(v reflect.Value) (float64, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(v.Len()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// compare 数值比较 (参数已在解析标签时校验)
+func compare(v reflect.Value, param string, fn func(actual, expected float64) bool) bool {
+	expected, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return false
+	}
+	actual, ok := measure(v)
+	return ok && fn(actual, expected)
+}
+
+// minRule 最小值/最小长度
+func minRule(v reflect.Value, param string) bool {
+	return compare(v, param, func(actual, expected float64) bool { return actual >= expected })
+}
+
+// maxRule 最大值/最大长度
+func maxRule(v reflect.Value, param string) bool {
+	return compare(v, param, func(actual, expected float64) bool { return actual <= expected })
+}
+
+// lenRule 固定值/固定长度
+func lenRule(v reflect.Value, param string) bool {
+	return compare(v, param, func(actual, expected float64) bool { return actual == expected })
+}
+
+// emailRule 邮箱格式
+func emailRule(v reflect.Value, _ string) bool {
+	return v.Kind() == reflect.String && emailRegexp.MatchString(v.String())
+}
+
+// oneOfRule 枚举值 (空格分隔)
+func oneOfRule(v reflect.Value, param string) bool {
+	actual := fmt.Sprint(v.Interface())
+	for _, option := range strings.Fields(param) {
+		if option == actual {
+			return true
+		}
+	}
+	return false
+}
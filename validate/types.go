@@ -0,0 +1,123 @@
+package validate
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/Anonymouscn/go-partner/base"
+)
+
+// CheckTypes 校验 map 数据与结构体字段类型是否匹配 (弥补 base.MapToStruct 静默忽略类型错误的问题)
+// m: json 解码后的 map, obj: 目标结构体 (或其指针); 返回 nil 或 ValidationErrors
+func CheckTypes(m map[string]any, obj any) error {
+	t := reflect.TypeOf(obj)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	errs := make(ValidationErrors, 0)
+	checkStruct(m, t, "", &errs)
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// checkStruct 校验结构体字段类型
+func checkStruct(m map[string]any, t reflect.Type, path string, errs *ValidationErrors) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		// 匿名结构字段映射到同层 map
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				checkStruct(m, ft, path, errs)
+			}
+			continue
+		}
+		key := base.GetJsonKeyOrDefaultFromStructField(field, base.CamelToSnake(field.Name))
+		raw, ok := m[key]
+		if !ok || raw == nil {
+			continue
+		}
+		checkValue(raw, field.Type, joinPath(path, key), field.Name, errs)
+	}
+}
+
+// checkValue 校验值类型
+func checkValue(raw any, t reflect.Type, path, name string, errs *ValidationErrors) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	expected, ok := matchType(raw, t)
+	if !ok {
+		*errs = append(*errs, newFieldError(path, name, "type", expected))
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if sm, ok := raw.(map[string]any); ok {
+			checkStruct(sm, t, path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i, item := range raw.([]any) {
+			if item != nil {
+				checkValue(item, t.Elem(), path+"["+strconv.Itoa(i)+"]", name, errs)
+			}
+		}
+	}
+}
+
+// matchType 判断 json 值是否可赋值到目标类型, 返回期望类型描述
+func matchType(raw any, t reflect.Type) (string, bool) {
+	if t == base.TimeType {
+		_, isNum := raw.(float64)
+		_, isStr := raw.(string)
+		return "timestamp", isNum || isStr
+	}
+	switch t.Kind() {
+	case reflect.String:
+		_, ok := raw.(string)
+		return "string", ok
+	case reflect.Bool:
+		_, ok := raw.(bool)
+		return "bool", ok
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		f, ok := toFloat(raw)
+		return "integer", ok && f == math.Trunc(f)
+	case reflect.Float32, reflect.Float64:
+		_, ok := toFloat(raw)
+		return "number", ok
+	case reflect.Struct, reflect.Map:
+		_, ok := raw.(map[string]any)
+		return "object", ok
+	case reflect.Slice, reflect.Array:
+		_, ok := raw.([]any)
+		return "array", ok
+	}
+	return t.Kind().String(), true
+}
+
+// toFloat json 数值转 float64
+func toFloat(raw any) (float64, bool) {
+	v := reflect.ValueOf(raw)
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	}
+	return 0, false
+}
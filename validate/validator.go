@@ -0,0 +1,310 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Anonymouscn/go-partner/base"
+)
+
+// TagName 校验标签名称
+const TagName = "validate"
+
+// FieldError 字段校验错误
+type FieldError struct {
+	Path    string `json:"path"`            // 字段 json 路径 (如 user.emails[0])
+	Field   string `json:"field"`           // 结构体字段名
+	Rule    string `json:"rule"`            // 未通过的规则
+	Param   string `json:"param,omitempty"` // 规则参数
+	Message string `json:"message"`         // 错误信息
+}
+
+func (err *FieldError) Error() string {
+	return err.Message
+}
+
+// ValidationErrors 校验错误列表
+type ValidationErrors []*FieldError
+
+func (errs ValidationErrors) Error() string {
+	var builder strings.Builder
+	builder.WriteString("Validation failed")
+	for i, err := range errs {
+		if i == 0 {
+			builder.WriteString(" - ")
+		} else {
+			builder.WriteString("; ")
+		}
+		builder.WriteString(err.Message)
+	}
+	return builder.String()
+}
+
+// rule 已解析的校验规则 (校验方法在校验时按名称查找, 注册覆盖后立即生效)
+type rule struct {
+	name  string // 规则名称
+	param string // 规则参数
+}
+
+// fieldRules 字段校验信息
+type fieldRules struct {
+	index     int    // 字段下标
+	name      string // 结构体字段名
+	key       string // json 键名
+	rules     []rule // 校验规则
+	omitempty bool   // 零值时跳过校验
+	required  bool   // 必填
+	embedded  bool   // 匿名结构字段 (展开到同层校验)
+}
+
+// ruleCache 结构体校验信息缓存 (仅缓存解析成功的结构体) map[reflect.Type][]*fieldRules
+var ruleCache sync.Map
+
+// Register 预先解析结构体校验标签 (含嵌套结构体), 建议启动时调用以提前暴露无效标签
+// 返回第一个未知规则或无效规则参数错误
+func Register(objs ...any) error {
+	for _, obj := range objs {
+		if err := registerType(reflect.TypeOf(obj), make(map[reflect.Type]bool)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerType 解析类型及其嵌套结构体的校验标签
+func registerType(t reflect.Type, visited map[reflect.Type]bool) error {
+	for t != nil {
+		switch t.Kind() {
+		case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+			if t == base.TimeType || visited[t] {
+				return nil
+			}
+			visited[t] = true
+			fields, err := parseRules(t)
+			if err != nil {
+				return err
+			}
+			for _, fr := range fields {
+				if err = registerType(t.Field(fr.index).Type, visited); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// Struct 校验结构体 (支持嵌套结构体, 匿名结构体, 切片/数组/map 元素及指针)
+// 返回 nil 或 ValidationErrors; 校验标签无效时返回解析错误
+func Struct(obj any) error {
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %v is not a struct", v.Kind())
+	}
+	errs := make(ValidationErrors, 0)
+	if err := validateStruct(v, "", &errs); err != nil {
+		return err
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// validateStruct 校验结构体
+func validateStruct(v reflect.Value, path string, errs *ValidationErrors) error {
+	fields, err := parseRules(v.Type())
+	if err != nil {
+		return err
+	}
+	for _, fr := range fields {
+		fv := v.Field(fr.index)
+		// 匿名结构字段展开到同层
+		if fr.embedded {
+			if err = validateNested(fv, path, errs); err != nil {
+				return err
+			}
+			continue
+		}
+		fp := joinPath(path, fr.key)
+		validateField(fv, fp, fr, errs)
+		if err = validateNested(fv, fp, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateField 校验字段
+func validateField(fv reflect.Value, path string, fr *fieldRules, errs *ValidationErrors) {
+	if isZero(fv) {
+		if fr.required {
+			*errs = append(*errs, newFieldError(path, fr.name, "required", ""))
+		}
+		if fr.omitempty || fr.required {
+			return
+		}
+	}
+	// 指针解引用, 空指针不继续校验
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return
+		}
+		fv = fv.Elem()
+	}
+	for _, r := range fr.rules {
+		if fn := lookupRule(r.name); fn != nil && !fn(fv, r.param) {
+			*errs = append(*errs, newFieldError(path, fr.name, r.name, r.param))
+		}
+	}
+}
+
+// validateNested 校验嵌套结构
+func validateNested(fv reflect.Value, path string, errs *ValidationErrors) error {
+	for fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface {
+		if fv.IsNil() {
+			return nil
+		}
+		fv = fv.Elem()
+	}
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() != base.TimeType {
+			return validateStruct(fv, path, errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			if err := validateNested(fv.Index(i), path+"["+strconv.Itoa(i)+"]", errs); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		iter := fv.MapRange()
+		for iter.Next() {
+			if err := validateNested(iter.Value(), fmt.Sprintf("%v[%v]", path, iter.Key().Interface()), errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseRules 解析结构体校验规则 (带缓存, 规则名称及参数在解析时校验)
+func parseRules(t reflect.Type) ([]*fieldRules, error) {
+	if cached, ok := ruleCache.Load(t); ok {
+		return cached.([]*fieldRules), nil
+	}
+	// 解析失败不缓存 (之后注册的自定义规则可使其解析成功)
+	fields, err := parseFields(t)
+	if err != nil {
+		return nil, err
+	}
+	ruleCache.Store(t, fields)
+	return fields, nil
+}
+
+// parseFields 解析结构体字段校验规则
+func parseFields(t reflect.Type) ([]*fieldRules, error) {
+	res := make([]*fieldRules, 0)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		// 忽略非公开字段
+		if !field.IsExported() {
+			continue
+		}
+		// 匿名结构字段映射到同层 (与 CheckTypes 一致)
+		if field.Anonymous {
+			ft := field.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				res = append(res, &fieldRules{index: i, name: field.Name, embedded: true})
+				continue
+			}
+		}
+		fr := &fieldRules{
+			index: i,
+			name:  field.Name,
+			key:   base.GetJsonKeyOrDefaultFromStructField(field, base.CamelToSnake(field.Name)),
+			rules: make([]rule, 0),
+		}
+		for _, item := range strings.Split(base.GetTagFromStructField(field, TagName), ",") {
+			name, param := splitRule(item)
+			switch name {
+			case "", "-":
+			case "omitempty":
+				fr.omitempty = true
+			case "required":
+				fr.required = true
+			default:
+				if lookupRule(name) == nil {
+					return nil, fmt.Errorf("validate: unknown rule %q on field %v.%v", name, t.Name(), field.Name)
+				}
+				if err := checkParam(name, param); err != nil {
+					return nil, fmt.Errorf("validate: %v on field %v.%v", err, t.Name(), field.Name)
+				}
+				fr.rules = append(fr.rules, rule{name: name, param: param})
+			}
+		}
+		res = append(res, fr)
+	}
+	return res, nil
+}
+
+// splitRule 拆分规则名称与参数 (min=1 => min, 1)
+func splitRule(item string) (string, string) {
+	item = strings.TrimSpace(item)
+	if i := strings.IndexByte(item, '='); i >= 0 {
+		return item[:i], item[i+1:]
+	}
+	return item, ""
+}
+
+// joinPath 拼接 json 路径
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// isZero 是否为零值 (空切片/map 视为零值)
+func isZero(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Struct:
+		if v.Type() == base.TimeType {
+			return v.Interface().(time.Time).IsZero()
+		}
+	}
+	return v.IsZero()
+}
+
+// newFieldError 新建字段校验错误
+func newFieldError(path, field, name, param string) *FieldError {
+	return &FieldError{
+		Path:    path,
+		Field:   field,
+		Rule:    name,
+		Param:   param,
+		Message: message(path, name, param),
+	}
+}
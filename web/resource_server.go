@@ -1 +1,315 @@
 package web
+
+import (
+	"embed"
+	"fmt"
+	"hash/fnv"
+	"html"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ResourceServer 静态资源服务
+type ResourceServer struct {
+	fsys  fs.FS                 // 资源文件系统 (目录或 embed.FS)
+	conf  *ResourceServerConfig // 配置
+	etags *sync.Map             // 内容摘要缓存 (仅不可变文件系统 embed.FS 启用) map[string]string
+}
+
+// ResourceServerConfig ResourceServer 配置
+type ResourceServerConfig struct {
+	Prefix        string        // 路由前缀 (匹配前剥离, 按路径段匹配, 不匹配时返回 404)
+	Index         string        // 首页文件名 (默认 index.html)
+	SPA           bool          // 单页应用模式 (资源不存在时回退到根目录首页)
+	Listing       bool          // 允许目录列表
+	Precompressed bool          // 优先使用预压缩文件 (.br/.gz)
+	MaxAge        time.Duration // 缓存时间 (Cache-Control: max-age, 0 不设置)
+}
+
+// precompressedEncodings 预压缩编码 (按优先级排序)
+var precompressedEncodings = []struct {
+	encoding string // 编码名称
+	ext      string // 文件后缀
+}{
+	{"br", ".br"},
+	{"gzip", ".gz"},
+}
+
+// NewResourceServer 新建静态资源服务 (基于目录)
+func NewResourceServer(dir string) *ResourceServer {
+	return NewResourceServerFS(os.DirFS(dir))
+}
+
+// NewResourceServerFS 新建静态资源服务 (基于 fs.FS, 如 embed.FS)
+func NewResourceServerFS(fsys fs.FS) *ResourceServer {
+	rs := &ResourceServer{
+		fsys: fsys,
+		conf: &ResourceServerConfig{
+			Index: "index.html", // 默认首页 index.html
+		},
+	}
+	// embed.FS 内容不可变, 内容摘要可按路径缓存
+	switch fsys.(type) {
+	case embed.FS, *embed.FS:
+		rs.etags = &sync.Map{}
+	}
+	return rs
+}
+
+// ApplyConfig 应用配置
+func (rs *ResourceServer) ApplyConfig(conf *ResourceServerConfig) *ResourceServer {
+	if conf.Index == "" {
+		conf.Index = "index.html"
+	}
+	rs.conf = conf
+	return rs
+}
+
+// ServeHTTP 处理 http 请求 (http.Handler 实现)
+func (rs *ResourceServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p := r.URL.Path
+	if prefix := strings.TrimSuffix(rs.conf.Prefix, "/"); prefix != "" {
+		// 前缀需匹配完整路径段 (/static 不匹配 /staticfoo)
+		if p != prefix && !strings.HasPrefix(p, prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+		p = strings.TrimPrefix(p, prefix)
+	}
+	rs.serve(w, r, p)
+}
+
+// HandlerFn 转换为 RestServer 处理方法
+// param: 通配路径参数名称, 如路由 /static/*filepath 对应 filepath
+func (rs *ResourceServer) HandlerFn(param string) HandlerFn {
+	return func(ctx *RestContext) {
+		ctx.written = true
+		rs.serve(ctx.Writer, ctx.Request, ctx.Param(param))
+	}
+}
+
+// serve 处理资源请求
+func (rs *ResourceServer) serve(w http.ResponseWriter, r *http.Request, p string) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	name, ok := resolvePath(p)
+	if !ok {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	info, err := fs.Stat(rs.fsys, name)
+	if err != nil {
+		rs.fallback(w, r, name)
+		return
+	}
+	if info.IsDir() {
+		rs.serveDir(w, r, name)
+		return
+	}
+	rs.serveFile(w, r, name, info)
+}
+
+// fallback 资源不存在处理 (SPA 模式回退首页)
+func (rs *ResourceServer) fallback(w http.ResponseWriter, r *http.Request, name string) {
+	if rs.conf.SPA && path.Ext(name) == "" {
+		if info, err := fs.Stat(rs.fsys, rs.conf.Index); err == nil && !info.IsDir() {
+			rs.serveFile(w, r, rs.conf.Index, info)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// serveDir 处理目录请求
+func (rs *ResourceServer) serveDir(w http.ResponseWriter, r *http.Request, name string) {
+	// 目录请求统一以 / 结尾, 保证相对路径正确
+	// 以请求路径判断并重定向到绝对路径 (路由通配参数不保留末尾 /)
+	if u := r.URL.Path; !strings.HasSuffix(u, "/") {
+		target := u + "/"
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+	index := path.Join(name, rs.conf.Index)
+	if info, err := fs.Stat(rs.fsys, index); err == nil && !info.IsDir() {
+		rs.serveFile(w, r, index, info)
+		return
+	}
+	if !rs.conf.Listing {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	rs.serveListing(w, name)
+}
+
+// serveListing 输出目录列表
+func (rs *ResourceServer) serveListing(w http.ResponseWriter, name string) {
+	entries, err := fs.ReadDir(rs.fsys, name)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name() < entries[j].Name()
+	})
+	var builder strings.Builder
+	builder.WriteString("<!doctype html>\n<pre>\n")
+	for _, entry := range entries {
+		n := entry.Name()
+		if entry.IsDir() {
+			n += "/"
+		}
+		u := url.URL{Path: n}
+		builder.WriteString(fmt.Sprintf("<a href=\"%s\">%s</a>\n", u.String(), html.EscapeString(n)))
+	}
+	builder.WriteString("</pre>\n")
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = io.WriteString(w, builder.String())
+}
+
+// serveFile 输出文件 (支持 ETag, Last-Modified, Range 及预压缩文件)
+func (rs *ResourceServer) serveFile(w http.ResponseWriter, r *http.Request, name string, info fs.FileInfo) {
+	target, encoding := name, ""
+	if rs.conf.Precompressed {
+		target, encoding, info = rs.negotiate(r, name, info)
+	}
+	f, err := rs.fsys.Open(target)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	defer func() { _ = f.Close() }()
+	content, ok := f.(io.ReadSeeker)
+	if !ok {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	header := w.Header()
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		header.Set("Content-Type", ctype)
+	}
+	if rs.conf.Precompressed {
+		header.Add("Vary", "Accept-Encoding")
+	}
+	if encoding != "" {
+		header.Set("Content-Encoding", encoding)
+	}
+	if rs.conf.MaxAge > 0 {
+		header.Set("Cache-Control", "public, max-age="+strconv.FormatInt(int64(rs.conf.MaxAge/time.Second), 10))
+	}
+	etag, err := rs.buildETag(target, info, content, encoding)
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	header.Set("ETag", etag)
+	// http.ServeContent 负责处理 Range, If-None-Match, If-Modified-Since 等条件请求
+	http.ServeContent(w, r, name, info.ModTime(), content)
+}
+
+// negotiate 协商预压缩文件
+func (rs *ResourceServer) negotiate(r *http.Request, name string, info fs.FileInfo) (string, string, fs.FileInfo) {
+	accept := r.Header.Get("Accept-Encoding")
+	for _, e := range precompressedEncodings {
+		if !acceptsEncoding(accept, e.encoding) {
+			continue
+		}
+		if ci, err := fs.Stat(rs.fsys, name+e.ext); err == nil && !ci.IsDir() {
+			return name + e.ext, e.encoding, ci
+		}
+	}
+	return name, "", info
+}
+
+// acceptsEncoding 是否接受指定编码
+func acceptsEncoding(accept, encoding string) bool {
+	for _, item := range strings.Split(accept, ",") {
+		parts := strings.Split(strings.TrimSpace(item), ";")
+		if !strings.EqualFold(strings.TrimSpace(parts[0]), encoding) {
+			continue
+		}
+		// 排除 q=0
+		for _, param := range parts[1:] {
+			if q := strings.TrimSpace(param); strings.HasPrefix(q, "q=") {
+				if v, err := strconv.ParseFloat(q[2:], 64); err == nil && v == 0 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// buildETag 生成 ETag (无修改时间的文件, 如 embed.FS, 使用内容摘要; 仅不可变文件系统按路径缓存摘要)
+func (rs *ResourceServer) buildETag(name string, info fs.FileInfo, content io.ReadSeeker, encoding string) (string, error) {
+	var tag string
+	if mt := info.ModTime(); !mt.IsZero() {
+		tag = strconv.FormatInt(mt.UnixNano(), 36) + "-" + strconv.FormatInt(info.Size(), 36)
+	} else if cached, ok := rs.cachedETag(name); ok {
+		tag = cached
+	} else {
+		h := fnv.New64a()
+		if _, err := io.Copy(h, content); err != nil {
+			return "", err
+		}
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		tag = strconv.FormatUint(h.Sum64(), 36) + "-" + strconv.FormatInt(info.Size(), 36)
+		if rs.etags != nil {
+			rs.etags.Store(name, tag)
+		}
+	}
+	if encoding != "" {
+		tag += "-" + encoding
+	}
+	return `"` + tag + `"`, nil
+}
+
+// cachedETag 获取缓存的内容摘要
+func (rs *ResourceServer) cachedETag(name string) (string, bool) {
+	if rs.etags == nil {
+		return "", false
+	}
+	cached, ok := rs.etags.Load(name)
+	if !ok {
+		return "", false
+	}
+	return cached.(string), true
+}
+
+// resolvePath 解析请求路径为 fs.FS 路径 (防止路径穿越)
+func resolvePath(p string) (string, bool) {
+	if strings.Contains(p, "\\") || strings.Contains(p, "\x00") {
+		return "", false
+	}
+	for _, seg := range strings.Split(p, "/") {
+		if seg == ".." {
+			return "", false
+		}
+	}
+	name := strings.TrimPrefix(path.Clean("/"+p), "/")
+	if name == "" {
+		name = "."
+	}
+	if !fs.ValidPath(name) {
+		return "", false
+	}
+	return name, true
+}
@@ -1 +1,530 @@
 package web
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Anonymouscn/go-partner/base"
+	restful_model "github.com/Anonymouscn/go-partner/restful/model"
+	"github.com/Anonymouscn/go-partner/validate"
+	"github.com/bytedance/sonic"
+)
+
+// HandlerFn 请求处理方法 (中间件与业务处理方法共用)
+type HandlerFn func(ctx *RestContext)
+
+// RestServer Restful 服务
+type RestServer struct {
+	RouteGroup                        // 根路由组
+	trees            map[string]*node // 路由树 map[请求方法]路由树根节点
+	server           *http.Server     // http 服务
+	NotFound         HandlerFn        // 路由不存在处理方法
+	MethodNotAllowed HandlerFn        // 请求方法不允许处理方法
+}
+
+// NewRestServer 新建 Restful 服务
+func NewRestServer() *RestServer {
+	s := &RestServer{
+		trees:            make(map[string]*node),
+		NotFound:         defaultNotFound,
+		MethodNotAllowed: defaultMethodNotAllowed,
+	}
+	s.RouteGroup = RouteGroup{server: s, prefix: "/"}
+	return s
+}
+
+// ServeHTTP 处理 http 请求 (http.Handler 实现)
+func (s *RestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := newRestContext(w, r)
+	path := cleanPath(r.URL.Path)
+	if root := s.trees[r.Method]; root != nil {
+		if n := root.match(splitPath(path), ctx.params); n != nil {
+			ctx.handlers = n.handlers
+			ctx.Next()
+			return
+		}
+	}
+	// 路由存在但请求方法不匹配
+	for method, root := range s.trees {
+		if method == r.Method {
+			continue
+		}
+		if root.match(splitPath(path), make(map[string]string)) != nil {
+			ctx.handlers = s.combine(s.MethodNotAllowed)
+			ctx.Next()
+			return
+		}
+	}
+	ctx.handlers = s.combine(s.NotFound)
+	ctx.Next()
+}
+
+// Run 启动服务 (阻塞)
+func (s *RestServer) Run(addr string) error {
+	s.server = &http.Server{Addr: addr, Handler: s}
+	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 优雅关闭服务
+func (s *RestServer) Shutdown(ctx context.Context) error {
+	if s.server == nil {
+		return nil
+	}
+	return s.server.Shutdown(ctx)
+}
+
+// addRoute 注册路由
+func (s *RestServer) addRoute(method, path string, handlers []HandlerFn) {
+	if len(handlers) == 0 {
+		panic(fmt.Sprintf("route %v %v has no handler", method, path))
+	}
+	root := s.trees[method]
+	if root == nil {
+		root = &node{}
+		s.trees[method] = root
+	}
+	root.insert(method, path, splitPath(cleanPath(path)), handlers)
+}
+
+// ====================================== 路由组 ====================================== //
+
+// RouteGroup 路由组
+type RouteGroup struct {
+	server      *RestServer // 所属服务
+	prefix      string      // 路由前缀
+	middlewares []HandlerFn // 中间件
+}
+
+// Use 注册中间件 (仅对之后注册的路由生效)
+func (g *RouteGroup) Use(middlewares ...HandlerFn) *RouteGroup {
+	g.middlewares = append(g.middlewares, middlewares...)
+	return g
+}
+
+// Group 新建子路由组
+func (g *RouteGroup) Group(prefix string, middlewares ...HandlerFn) *RouteGroup {
+	return &RouteGroup{
+		server:      g.server,
+		prefix:      joinPath(g.prefix, prefix),
+		middlewares: g.combine(middlewares...),
+	}
+}
+
+// Handle 注册指定请求方法路由
+func (g *RouteGroup) Handle(method, path string, handlers ...HandlerFn) *RouteGroup {
+	g.server.addRoute(strings.ToUpper(method), joinPath(g.prefix, path), g.combine(handlers...))
+	return g
+}
+
+// GET 注册 GET 路由
+func (g *RouteGroup) GET(path string, handlers ...HandlerFn) *RouteGroup {
+	return g.Handle(http.MethodGet, path, handlers...)
+}
+
+// POST 注册 POST 路由
+func (g *RouteGroup) POST(path string, handlers ...HandlerFn) *RouteGroup {
+	return g.Handle(http.MethodPost, path, handlers...)
+}
+
+// PUT 注册 PUT 路由
+func (g *RouteGroup) PUT(path string, handlers ...HandlerFn) *RouteGroup {
+	return g.Handle(http.MethodPut, path, handlers...)
+}
+
+// PATCH 注册 PATCH 路由
+func (g *RouteGroup) PATCH(path string, handlers ...HandlerFn) *RouteGroup {
+	return g.Handle(http.MethodPatch, path, handlers...)
+}
+
+// DELETE 注册 DELETE 路由
+func (g *RouteGroup) DELETE(path string, handlers ...HandlerFn) *RouteGroup {
+	return g.Handle(http.MethodDelete, path, handlers...)
+}
+
+// combine 合并中间件与处理方法 (复制切片, 避免路由间相互影响)
+func (g *RouteGroup) combine(handlers ...HandlerFn) []HandlerFn {
+	res := make([]HandlerFn, 0, len(g.middlewares)+len(handlers))
+	res = append(res, g.middlewares...)
+	return append(res, handlers...)
+}
+
+// ====================================== 路由树 ====================================== //
+
+// node 路由树节点
+type node struct {
+	children map[string]*node // 静态子节点
+	param    *node            // 路径参数子节点 (:name)
+	wildcard *node            // 通配子节点 (*name, 仅允许出现在末尾)
+	name     string           // 参数名称
+	handlers []HandlerFn      // 处理方法链
+}
+
+// insert 插入路由
+func (n *node) insert(method, path string, segments []string, handlers []HandlerFn) {
+	cur := n
+	for i, seg := range segments {
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			if cur.param == nil {
+				cur.param = &node{name: seg[1:]}
+			} else if cur.param.name != seg[1:] {
+				panic(fmt.Sprintf("route %v %v conflicts with param :%v", method, path, cur.param.name))
+			}
+			cur = cur.param
+		case strings.HasPrefix(seg, "*"):
+			if i != len(segments)-1 {
+				panic(fmt.Sprintf("route %v %v: wildcard must be the last segment", method, path))
+			}
+			if cur.wildcard == nil {
+				cur.wildcard = &node{name: seg[1:]}
+			}
+			cur = cur.wildcard
+		default:
+			if cur.children == nil {
+				cur.children = make(map[string]*node)
+			}
+			child := cur.children[seg]
+			if child == nil {
+				child = &node{}
+				cur.children[seg] = child
+			}
+			cur = child
+		}
+	}
+	if cur.handlers != nil {
+		panic(fmt.Sprintf("route %v %v is already registered", method, path))
+	}
+	cur.handlers = handlers
+}
+
+// match 匹配路由 (优先级: 静态 > 参数 > 通配)
+func (n *node) match(segments []string, params map[string]string) *node {
+	if len(segments) == 0 {
+		if n.handlers != nil {
+			return n
+		}
+		if n.wildcard != nil {
+			params[n.wildcard.name] = ""
+			return n.wildcard
+		}
+		return nil
+	}
+	seg := segments[0]
+	if child := n.children[seg]; child != nil {
+		if res := child.match(segments[1:], params); res != nil {
+			return res
+		}
+	}
+	if n.param != nil {
+		if res := n.param.match(segments[1:], params); res != nil {
+			params[n.param.name] = seg
+			return res
+		}
+	}
+	if n.wildcard != nil {
+		params[n.wildcard.name] = strings.Join(segments, "/")
+		return n.wildcard
+	}
+	return nil
+}
+
+// cleanPath 规范化路径
+func cleanPath(path string) string {
+	if path == "" || path[0] != '/' {
+		path = "/" + path
+	}
+	return path
+}
+
+// splitPath 拆分路径
+func splitPath(path string) []string {
+	res := make([]string, 0)
+	for _, seg := range strings.Split(path, "/") {
+		if seg != "" {
+			res = append(res, seg)
+		}
+	}
+	return res
+}
+
+// joinPath 拼接路径
+func joinPath(prefix, path string) string {
+	return "/" + strings.Join(append(splitPath(prefix), splitPath(path)...), "/")
+}
+
+// ====================================== 请求上下文 ====================================== //
+
+// abortIndex 中止执行时的处理方法下标
+const abortIndex = 1 << 30
+
+// RestContext 请求上下文
+type RestContext struct {
+	Request  *http.Request       // http 请求
+	Writer   http.ResponseWriter // http 响应
+	params   map[string]string   // 路径参数
+	handlers []HandlerFn         // 处理方法链
+	index    int                 // 当前处理方法下标
+	keys     map[string]any      // 上下文数据
+	written  bool                // 是否已写入响应
+}
+
+// newRestContext 新建请求上下文
+func newRestContext(w http.ResponseWriter, r *http.Request) *RestContext {
+	return &RestContext{
+		Request: r,
+		Writer:  w,
+		params:  make(map[string]string),
+		index:   -1,
+	}
+}
+
+// Next 执行后续处理方法 (供中间件调用)
+func (ctx *RestContext) Next() {
+	ctx.index++
+	for ctx.index < len(ctx.handlers) {
+		ctx.handlers[ctx.index](ctx)
+		ctx.index++
+	}
+}
+
+// Abort 中止后续处理方法
+func (ctx *RestContext) Abort() {
+	ctx.index = abortIndex
+}
+
+// IsAborted 是否已中止
+func (ctx *RestContext) IsAborted() bool {
+	return ctx.index >= abortIndex
+}
+
+// Set 设置上下文数据
+func (ctx *RestContext) Set(key string, value any) {
+	if ctx.keys == nil {
+		ctx.keys = make(map[string]any)
+	}
+	ctx.keys[key] = value
+}
+
+// Get 获取上下文数据
+func (ctx *RestContext) Get(key string) (any, bool) {
+	v, ok := ctx.keys[key]
+	return v, ok
+}
+
+// Param 获取路径参数
+func (ctx *RestContext) Param(name string) string {
+	return ctx.params[name]
+}
+
+// Params 获取全部路径参数
+func (ctx *RestContext) Params() map[string]string {
+	return ctx.params
+}
+
+// Query 获取请求行参数
+func (ctx *RestContext) Query(name string) string {
+	return ctx.Request.URL.Query().Get(name)
+}
+
+// PageQuery 获取分页查询参数 (请求行参数 page, size, cursor; 已规范化)
+func (ctx *RestContext) PageQuery() *restful_model.PageQuery {
+	query := ctx.Request.URL.Query()
+	page, _ := strconv.Atoi(query.Get("page"))
+	size, _ := strconv.Atoi(query.Get("size"))
+	q := &restful_model.PageQuery{
+		Page:   page,
+		Size:   size,
+		Cursor: query.Get("cursor"),
+	}
+	return q.Normalize()
+}
+
+// Bind 绑定 json 请求体到结构 (字段弱校验, 复用 base.MapToStruct)
+func (ctx *RestContext) Bind(v any) error {
+	m, err := ctx.decodeBody()
+	if err != nil || m == nil {
+		return err
+	}
+	base.MapToStruct(m, v)
+	return nil
+}
+
+// BindAndValidate 绑定 json 请求体到结构并校验 (字段类型 + validate 标签)
+// 校验失败返回 validate.ValidationErrors
+func (ctx *RestContext) BindAndValidate(v any) error {
+	m, err := ctx.decodeBody()
+	if err != nil {
+		return err
+	}
+	if m != nil {
+		if err := validate.CheckTypes(m, v); err != nil {
+			return err
+		}
+		base.MapToStruct(m, v)
+	}
+	return validate.Struct(v)
+}
+
+// MustBind 绑定 json 请求体到结构并校验, 失败时响应参数错误并中止后续处理
+func (ctx *RestContext) MustBind(v any) bool {
+	err := ctx.BindAndValidate(v)
+	if err == nil {
+		return true
+	}
+	var errs validate.ValidationErrors
+	if errors.As(err, &errs) {
+		ctx.Error(errs)
+	} else {
+		ctx.Error(restful_model.NewError(restful_model.CodeInvalidParams, err.Error()))
+	}
+	ctx.Abort()
+	return false
+}
+
+// decodeBody 解码 json 请求体 (空请求体返回 nil)
+func (ctx *RestContext) decodeBody() (map[string]any, error) {
+	if ctx.Request.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		return nil, err
+	}
+	if len(body) == 0 {
+		return nil, nil
+	}
+	m := make(map[string]any)
+	if err := sonic.Unmarshal(body, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Written 是否已写入响应
+func (ctx *RestContext) Written() bool {
+	return ctx.written
+}
+
+// Status 写入响应状态码
+func (ctx *RestContext) Status(status int) {
+	if ctx.written {
+		return
+	}
+	ctx.written = true
+	ctx.Writer.WriteHeader(status)
+}
+
+// JSON 写入 json 响应
+func (ctx *RestContext) JSON(status int, v any) {
+	data, err := sonic.Marshal(v)
+	if err != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	ctx.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	ctx.Status(status)
+	_, _ = ctx.Writer.Write(data)
+}
+
+// Success 响应成功
+func (ctx *RestContext) Success() {
+	ctx.Result(restful_model.Success())
+}
+
+// SuccessWithData 响应成功 (带数据)
+func (ctx *RestContext) SuccessWithData(data any) {
+	ctx.Result(restful_model.SuccessWithData(data))
+}
+
+// Reply 普通响应
+func (ctx *RestContext) Reply(code int, msg string) {
+	ctx.Result(restful_model.Reply(code, msg))
+}
+
+// ReplyWithData 普通响应 (带数据)
+func (ctx *RestContext) ReplyWithData(code int, msg string, data any) {
+	ctx.Result(restful_model.ReplyWithData(code, msg, data))
+}
+
+// Result 写入业务结果 (业务状态码为合法 http 状态码时作为响应状态码, 否则响应 200)
+func (ctx *RestContext) Result(result *restful_model.Result[any]) {
+	ctx.JSON(httpStatusOf(result.Code), result)
+}
+
+// Error 根据错误写入响应 (请求 Accept 包含 problem+json 时响应问题详情, 否则响应业务结果)
+func (ctx *RestContext) Error(err error) {
+	if strings.Contains(ctx.Request.Header.Get("Accept"), restful_model.ProblemContentType) {
+		ctx.Problem(err)
+		return
+	}
+	ctx.JSON(restful_model.StatusOf(err), restful_model.FromErrorWithLocale(err, ctx.Locale()))
+}
+
+// Problem 根据错误写入 problem+json 响应
+func (ctx *RestContext) Problem(err error) {
+	p := restful_model.ProblemFromErrorWithLocale(err, ctx.Locale())
+	if p.Instance == "" {
+		p.Instance = ctx.Request.URL.Path
+	}
+	data, e := sonic.Marshal(p)
+	if e != nil {
+		ctx.Status(http.StatusInternalServerError)
+		return
+	}
+	ctx.Writer.Header().Set("Content-Type", restful_model.ProblemContentType)
+	ctx.Status(p.Status)
+	_, _ = ctx.Writer.Write(data)
+}
+
+// Locale 获取请求首选语言 (Accept-Language 首项)
+func (ctx *RestContext) Locale() string {
+	accept := ctx.Request.Header.Get("Accept-Language")
+	if accept == "" {
+		return ""
+	}
+	locale := strings.Split(accept, ",")[0]
+	return strings.TrimSpace(strings.Split(locale, ";")[0])
+}
+
+// httpStatusOf 业务状态码转 http 状态码
+func httpStatusOf(code int) int {
+	if code >= 100 && code <= 599 {
+		return code
+	}
+	return http.StatusOK
+}
+
+// ====================================== 内置处理方法 ====================================== //
+
+// defaultNotFound 默认路由不存在处理方法
+func defaultNotFound(ctx *RestContext) {
+	ctx.Error(restful_model.CodeNotFound)
+}
+
+// defaultMethodNotAllowed 默认请求方法不允许处理方法
+func defaultMethodNotAllowed(ctx *RestContext) {
+	ctx.Error(restful_model.CodeMethodNotAllowed)
+}
+
+// Recovery panic 恢复中间件
+func Recovery() HandlerFn {
+	return func(ctx *RestContext) {
+		defer func() {
+			if r := recover(); r != nil {
+				ctx.Abort()
+				if !ctx.Written() {
+					ctx.Error(fmt.Errorf("panic: %v", r))
+				}
+			}
+		}()
+		ctx.Next()
+	}
+}